@@ -5,17 +5,30 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"math/rand"
 	"net/http"
 	"net/http/httputil"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
 // LocalError represents a client-side error, i.e. client can't build the request or parse the response
+//
+// Body is the raw response body if it couldn't be decoded.
 type LocalError struct {
 	Reason string
 	Inner  error
+	Body   []byte
 }
 
 func (e LocalError) Error() string {
@@ -44,40 +57,240 @@ func (e TransportError) Unwrap() error {
 }
 
 // ApplicationError represents an error on the application layer, i.e. http status code > 2xx
+//
+// RetryAfter is the delay requested by the server with the Retry-After header, zero if there was none. Remaining is the
+// request quota left as reported in the X-RateLimit-Remaining header, -1 if there was none.
 type ApplicationError struct {
-	v interface{}
+	StatusCode int
+	RetryAfter time.Duration
+	Remaining  int
+	v          interface{}
 }
 
 func (e ApplicationError) Error() string {
 	return fmt.Sprintf("application error: %v", e.v)
 }
 
+// Failure returns the decoded body of the failed response, i.e. the targetFailure passed to Client.Do
+func (e ApplicationError) Failure() interface{} {
+	return e.v
+}
+
+// IsRateLimited tells if the request was rejected because of hitting the rate limit
+func (e ApplicationError) IsRateLimited() bool {
+	return e.StatusCode == http.StatusTooManyRequests
+}
+
+// IsConflict tells if the request failed because of a conflicting concurrent change, i.e. the decoded failure has the
+// conflict_error code, see ErrorCoder
+func (e ApplicationError) IsConflict() bool {
+	coder, ok := e.v.(ErrorCoder)
+	return ok && coder.ErrorCode() == conflictErrorCode
+}
+
+// ErrorCoder is implemented by the failure targets passed to Client.Do which carry the error code sent by the server
+type ErrorCoder interface {
+	ErrorCode() string
+}
+
+const conflictErrorCode = "conflict_error"
+
+// retryable tells if the request which failed with this error is worth retrying, i.e. it was rate-limited or failed
+// because of a server fault; the other client errors (4xx) would fail again
+//
+// The server faults of the POST requests are not retried, the server might have created the object before failing.
+func (e ApplicationError) retryable(method string) bool {
+	if e.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	return e.StatusCode >= 500 && method != http.MethodPost
+}
+
+// BodyEncoder encodes a request body along with its content type
+//
+// A body passed to Client.Do which implements BodyEncoder is sent as it encodes itself, any other body is encoded as
+// JSON.
+type BodyEncoder interface {
+	ContentType() string
+	Encode() (io.Reader, error)
+}
+
+// jsonBody is the default BodyEncoder
+type jsonBody struct {
+	v interface{}
+}
+
+func (b jsonBody) ContentType() string {
+	return "application/json"
+}
+
+func (b jsonBody) Encode() (io.Reader, error) {
+	buf, err := json.Marshal(b.v)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewBuffer(buf), nil
+}
+
+// RequestLog describes a single request made by the client, see Logger
+type RequestLog struct {
+	Method string
+	Path   string
+	// StatusCode is zero if there was no response, Err is set then
+	StatusCode int
+	Duration   time.Duration
+	Err        error
+	// Warning is the warning sent by the server in the Notion-Warning or Warning response header, if any
+	Warning string
+}
+
+// Logger receives a structured record of every request made by the client, including the retries
+type Logger interface {
+	LogRequest(ctx context.Context, entry RequestLog)
+}
+
+// ResponseMeta carries the metadata of a response, see WithResponseMeta
+type ResponseMeta struct {
+	StatusCode int
+	// RequestID is the X-Request-Id response header, the id to quote when reporting problems to the API provider
+	RequestID string
+	Header    http.Header
+}
+
+type responseMetaKey struct{}
+
+// WithResponseMeta returns a context which makes Client.Do record the metadata of the response into meta
+//
+// With retries meta describes the last response.
+func WithResponseMeta(ctx context.Context, meta *ResponseMeta) context.Context {
+	return context.WithValue(ctx, responseMetaKey{}, meta)
+}
+
+type requestHeaderKey struct{}
+
+// WithRequestHeader returns a context which makes Client.Do send the header with the request, e.g. If-None-Match
+//
+// The header overrides the one with the same name set in Options.AddHeaders.
+func WithRequestHeader(ctx context.Context, header, value string) context.Context {
+	headers := http.Header{}
+	if prev, ok := ctx.Value(requestHeaderKey{}).(http.Header); ok {
+		headers = prev.Clone()
+	}
+	headers.Set(header, value)
+	return context.WithValue(ctx, requestHeaderKey{}, headers)
+}
+
+type requestIDKey struct{}
+
+// WithRequestID returns a context which makes Client.Do send the id in the Options.RequestIDHeader header, e.g. to
+// correlate the request with the call which caused it
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// ErrNotModified is returned by Client.Do when the server responds with 304 Not Modified to a conditional request,
+// the target isn't decoded then
+var ErrNotModified = errors.New("not modified")
+
 // Options can customize Client behavior
 type Options struct {
 	RootURL    string
 	AddHeaders map[string]string
-	Trace      bool
+	// Trace enables logging of every request and response, including the method, URL, headers, status code and
+	// bodies, to TraceWriter. The Authorization header is redacted.
+	Trace bool
+	// TraceWriter is where the trace is written to, os.Stderr if not set.
+	TraceWriter io.Writer
+	// MaxRetries is the number of times a rate-limited (429) or failed with a server error (5xx) request is retried,
+	// waiting for the duration from the Retry-After header, or backing off without it, between the attempts. The
+	// server errors of POST requests are not retried, as the object might have been created. The requests which
+	// failed with a transport error are retried as well, see BackoffBase. Zero disables retries.
+	MaxRetries int
+	// BackoffBase is the delay before the first retry of a request which failed with a transport error or without
+	// the Retry-After header, it doubles with every next retry and is randomized to spread the retries. Defaults to
+	// 100ms.
+	BackoffBase time.Duration
+	// OnWarning is called with the warning sent by the server in the Notion-Warning or Warning response header, e.g.
+	// when the endpoint is deprecated. If it's not set the warnings go to the Logger, see RequestLog.Warning, or to the
+	// trace. Without either of them a warning is logged to stderr once per method and path.
+	OnWarning func(method, path, warning string)
+	// HedgeDelay enables hedging of the GET requests: if there's no response within the delay a second, identical
+	// request is sent and the response which comes first is used, the other request is cancelled. Zero disables it.
+	HedgeDelay time.Duration
+	// OnQuota is called with the remaining request quota whenever the server reports it in the X-RateLimit-Remaining
+	// response header, so that the callers can slow down before getting rate-limited.
+	OnQuota func(method, path string, remaining int)
+	// Timeout limits the time of each request, including reading the response. A shorter deadline of the caller's
+	// context still applies. Zero means no timeout.
+	Timeout time.Duration
+	// Middleware wraps the transport of the http client, e.g. to add tracing or metrics around every request. The
+	// first middleware is the outermost one, i.e. it sees the request first and the response last.
+	Middleware []func(http.RoundTripper) http.RoundTripper
+	// Logger gets a record of every request, nothing is logged if it's not set.
+	Logger Logger
+	// StrictDecode makes decoding a successful response fail on the fields the target doesn't know, e.g. to catch
+	// a mismatch between the Notion-Version sent and the shape of the responses. The error responses are decoded
+	// leniently.
+	StrictDecode bool
+	// RequestIDHeader is the header the request ID set with WithRequestID is sent in, e.g. X-Correlation-Id. No request
+	// ID is sent if it's not set.
+	RequestIDHeader string
+	// RetryOnConflict makes the requests which failed with the conflict_error code retried like the transport errors,
+	// see ErrorCoder. The retries are bounded by MaxRetries.
+	RetryOnConflict bool
 }
 
 // Client is a wrapper over http.Client to make it easier to use from the notion API
 type Client struct {
 	httpClient *http.Client
 	opts       *Options
+	tracer     *log.Logger
+	// stderr logs the warnings no one else is told about, warned keeps the method and path already warned about
+	stderr *log.Logger
+	warned sync.Map
 }
 
 // New creates a Client with provided options
 func New(httpClient *http.Client, opts Options) *Client {
+	if len(opts.Middleware) > 0 {
+		httpClient = wrapTransport(httpClient, opts.Middleware)
+	}
+	traceWriter := opts.TraceWriter
+	if traceWriter == nil {
+		traceWriter = os.Stderr
+	}
 	return &Client{
 		httpClient: httpClient,
 		opts:       &opts,
+		tracer:     log.New(traceWriter, "", log.LstdFlags),
+		stderr:     log.New(os.Stderr, "", log.LstdFlags),
 	}
 }
 
+// wrapTransport returns a copy of the http client with the transport wrapped in the middleware, the original client
+// (often http.DefaultClient) is left untouched
+func wrapTransport(httpClient *http.Client, middleware []func(http.RoundTripper) http.RoundTripper) *http.Client {
+	wrapped := *httpClient
+	transport := wrapped.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	for i := len(middleware) - 1; i >= 0; i-- {
+		transport = middleware[i](transport)
+	}
+	wrapped.Transport = transport
+	return &wrapped
+}
+
 // Do issues a request with given params.
 //
 // In case of 2xx response decode the response body into targetSuccess.
-// In case of >2xx response return ApplicationError and try to decode the body into targetFailure
+// In case of 3xx response, i.e. a redirect the http client didn't follow, return ApplicationError.
+// In case of 4xx or 5xx response return ApplicationError and try to decode the body into targetFailure
 // May return one of ApplicationError, LocalError, TransportError in case of a failure
+//
+// Rate-limited and server error responses, as well as transport errors, are retried up to Options.MaxRetries times.
+// Retrying stops as soon as ctx is done, the returned LocalError then wraps the context error.
 func (c *Client) Do(
 	ctx context.Context,
 	method string,
@@ -87,35 +300,154 @@ func (c *Client) Do(
 	targetSuccess interface{},
 	targetFailure interface{},
 ) error {
+	var values url.Values
+	if len(query) > 0 {
+		values = make(url.Values, len(query))
+		for k, v := range query {
+			values.Set(k, v)
+		}
+	}
+	return c.DoValues(ctx, method, path, values, body, targetSuccess, targetFailure)
+}
+
+// DoValues issues a request like Do, the query can have repeated parameters, e.g.
+// filter_properties=a&filter_properties=b
+func (c *Client) DoValues(
+	ctx context.Context,
+	method string,
+	path string,
+	query url.Values,
+	body interface{},
+	targetSuccess interface{},
+	targetFailure interface{},
+) error {
+	for attempt := 0; ; attempt++ {
+		err := c.attempt(ctx, method, path, query, body, targetSuccess, targetFailure)
+		delay, retryable := c.retryDelay(method, err, attempt)
+		if attempt >= c.opts.MaxRetries || !retryable {
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return LocalError{Reason: fmt.Sprintf("not retrying after %v", err), Inner: ctxErr}
+		}
+		if waitErr := wait(ctx, delay); waitErr != nil {
+			return LocalError{Reason: fmt.Sprintf("not retrying after %v", err), Inner: waitErr}
+		}
+	}
+}
+
+// attempt makes a single request, limited by Options.Timeout if set
+func (c *Client) attempt(
+	ctx context.Context,
+	method string,
+	path string,
+	query url.Values,
+	body interface{},
+	targetSuccess interface{},
+	targetFailure interface{},
+) error {
+	if c.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.opts.Timeout)
+		defer cancel()
+	}
 	req, err := c.newRequest(ctx, method, path, query, body)
 	if err != nil {
 		return err
 	}
-
 	return c.do(req, targetSuccess, targetFailure)
 }
 
+// retryDelay tells if the request which failed with err is worth retrying and how long to wait before that
+//
+// The server tells how long to wait with the Retry-After header, the responses without it, the transport errors and the
+// conflicts (if enabled) are retried with an exponential backoff.
+func (c *Client) retryDelay(method string, err error, attempt int) (time.Duration, bool) {
+	base := c.opts.BackoffBase
+	if base <= 0 {
+		base = defaultBackoffBase
+	}
+	var appErr ApplicationError
+	if errors.As(err, &appErr) {
+		if c.opts.RetryOnConflict && appErr.IsConflict() {
+			return backoff(base, attempt), true
+		}
+		if !appErr.retryable(method) {
+			return 0, false
+		}
+		if appErr.RetryAfter > 0 {
+			return appErr.RetryAfter, true
+		}
+		return backoff(base, attempt), true
+	}
+	var transportErr TransportError
+	if errors.As(err, &transportErr) {
+		return backoff(base, attempt), true
+	}
+	return 0, false
+}
+
+const (
+	defaultBackoffBase = 100 * time.Millisecond
+	maxBackoffShift    = 10
+)
+
+// backoff returns a random delay between half and the whole of base * 2^attempt
+func backoff(base time.Duration, attempt int) time.Duration {
+	if attempt > maxBackoffShift {
+		attempt = maxBackoffShift
+	}
+	d := base << uint(attempt)
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// wait blocks for the duration d, it gives up early if the ctx is done or its deadline comes before d elapses
+func wait(ctx context.Context, d time.Duration) error {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+		return context.DeadlineExceeded
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 func (c *Client) newRequest(
 	ctx context.Context,
 	method string,
 	path string,
-	query map[string]string,
+	query url.Values,
 	body interface{},
 ) (*http.Request, error) {
-	buf, err := c.encode(body)
-	if err != nil {
-		return nil, LocalError{Reason: "failed to encode the body", Inner: err}
+	encoder, ok := body.(BodyEncoder)
+	if !ok {
+		encoder = jsonBody{v: body}
+	}
+	// requests without a body, e.g. GET or DELETE, don't send anything, not even a JSON null
+	var buf io.Reader
+	if body != nil {
+		var err error
+		buf, err = encoder.Encode()
+		if err != nil {
+			return nil, LocalError{Reason: "failed to encode the body", Inner: err}
+		}
 	}
 
-	req, err := http.NewRequest(method, c.opts.RootURL+path, buf)
+	req, err := http.NewRequest(method, joinURL(c.opts.RootURL, path), buf)
 	if err != nil {
 		return nil, LocalError{Reason: "failed to create GET request", Inner: err}
 	}
 
 	if len(query) > 0 {
 		q := req.URL.Query()
-		for k, v := range query {
-			q.Add(k, v)
+		for k, vs := range query {
+			for _, v := range vs {
+				q.Add(k, v)
+			}
 		}
 		req.URL.RawQuery = q.Encode()
 	}
@@ -123,9 +455,17 @@ func (c *Client) newRequest(
 	for header, val := range c.opts.AddHeaders {
 		req.Header.Add(header, val)
 	}
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok && id != "" && c.opts.RequestIDHeader != "" {
+		req.Header.Set(c.opts.RequestIDHeader, id)
+	}
+	if headers, ok := ctx.Value(requestHeaderKey{}).(http.Header); ok {
+		for header, vals := range headers {
+			req.Header[header] = vals
+		}
+	}
 
 	if body != nil {
-		req.Header.Add("Content-Type", "application/json")
+		req.Header.Add("Content-Type", encoder.ContentType())
 	}
 
 	req = req.WithContext(ctx)
@@ -137,13 +477,15 @@ func (c *Client) do(r *http.Request, targetSuccess interface{}, targetFailure in
 	if c.opts.Trace {
 		body, err := httputil.DumpRequestOut(r, true)
 		if err != nil {
-			log.Printf("Trace request: %v", err)
+			c.tracer.Printf("Trace request: %v", err)
 		} else {
-			log.Printf("Trace request:\n%s\n", string(body))
+			c.tracer.Printf("Trace request:\n%s\n", redactAuthorization(body))
 		}
 	}
 
-	resp, err := c.httpClient.Do(r)
+	start := time.Now()
+	resp, err := c.send(r)
+	c.log(r, resp, err, time.Since(start))
 	if err != nil {
 		return TransportError{URL: r.URL.String(), Inner: err}
 	}
@@ -151,37 +493,332 @@ func (c *Client) do(r *http.Request, targetSuccess interface{}, targetFailure in
 	if c.opts.Trace {
 		body, err := httputil.DumpResponse(resp, true)
 		if err != nil {
-			log.Printf("Trace response: %v", err)
+			c.tracer.Printf("Trace response: %v", err)
 		} else {
-			log.Printf("Trace response:\n%s\n", string(body))
+			c.tracer.Printf("Trace response:\n%s\n", string(body))
 		}
 	}
 
+	c.warn(r, resp)
+	if meta, ok := r.Context().Value(responseMetaKey{}).(*ResponseMeta); ok {
+		*meta = ResponseMeta{StatusCode: resp.StatusCode, RequestID: resp.Header.Get("X-Request-Id"), Header: resp.Header}
+	}
+	remaining, ok := parseRemaining(resp.Header.Get("X-RateLimit-Remaining"))
+	if ok && c.opts.OnQuota != nil {
+		c.opts.OnQuota(r.Method, r.URL.Path, remaining)
+	}
+
 	defer resp.Body.Close()
-	if resp.StatusCode <= 300 {
-		if err := c.decode(resp, targetSuccess); err != nil {
-			return LocalError{Reason: "can't decode successful response", Inner: err}
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		if body, err := c.decode(resp, targetSuccess, c.opts.StrictDecode); err != nil {
+			return decodeError("successful", body, err)
 		}
 		return nil
+	case resp.StatusCode == http.StatusNotModified:
+		return ErrNotModified
+	case resp.StatusCode >= 300 && resp.StatusCode < 400:
+		// The http client follows the redirects it can, so this one has nowhere to go and no API error in the body
+		return ApplicationError{
+			StatusCode: resp.StatusCode,
+			Remaining:  remaining,
+			v:          fmt.Sprintf("unexpected redirect %d to %q", resp.StatusCode, resp.Header.Get("Location")),
+		}
 	}
-	if err := c.decode(resp, targetFailure); err != nil {
-		return LocalError{Reason: "can't decode failure response", Inner: err}
+	if body, err := c.decode(resp, targetFailure, false); err != nil {
+		return decodeError("failure", body, err)
+	}
+	return ApplicationError{
+		StatusCode: resp.StatusCode,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		Remaining:  remaining,
+		v:          targetFailure,
 	}
-	return ApplicationError{v: targetFailure}
 }
 
-func (c *Client) encode(v interface{}) (io.Reader, error) {
-	buf, err := json.Marshal(v)
-	if err != nil {
-		return nil, err
+// send makes the request, hedging it if enabled and the request is idempotent
+func (c *Client) send(r *http.Request) (*http.Response, error) {
+	if c.opts.HedgeDelay <= 0 || r.Method != http.MethodGet {
+		return c.httpClient.Do(r)
 	}
-	return bytes.NewBuffer(buf), nil
+	return c.hedge(r)
+}
+
+type hedgeResult struct {
+	attempt int
+	resp    *http.Response
+	err     error
+	cancel  context.CancelFunc
+}
+
+// hedge sends the request and, if there's no response after HedgeDelay, sends it again
+//
+// The first successful response wins, the request still in flight is cancelled.
+func (c *Client) hedge(r *http.Request) (*http.Response, error) {
+	results := make(chan hedgeResult, 2)
+	var cancels []context.CancelFunc
+	start := func() {
+		ctx, cancel := context.WithCancel(r.Context())
+		attempt := len(cancels)
+		cancels = append(cancels, cancel)
+		req := r.Clone(ctx)
+		if r.GetBody != nil {
+			req.Body, _ = r.GetBody()
+		}
+		go func() {
+			resp, err := c.httpClient.Do(req)
+			results <- hedgeResult{attempt: attempt, resp: resp, err: err, cancel: cancel}
+		}()
+	}
+
+	timer := time.NewTimer(c.opts.HedgeDelay)
+	defer timer.Stop()
+	start()
+	pending := 1
+	var err error
+	for pending > 0 {
+		select {
+		case <-timer.C:
+			start()
+			pending++
+		case res := <-results:
+			pending--
+			if res.err != nil {
+				res.cancel()
+				err = res.err
+				continue
+			}
+			for attempt, cancel := range cancels {
+				if attempt != res.attempt {
+					cancel()
+				}
+			}
+			go drain(results, pending)
+			res.resp.Body = cancelOnClose{ReadCloser: res.resp.Body, cancel: res.cancel}
+			return res.resp, nil
+		}
+	}
+	return nil, err
+}
+
+// drain discards the n responses which lost the race
+func drain(results <-chan hedgeResult, n int) {
+	for i := 0; i < n; i++ {
+		res := <-results
+		if res.resp != nil {
+			res.resp.Body.Close()
+		}
+		res.cancel()
+	}
+}
+
+// cancelOnClose releases the request context once the response body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+func (c *Client) log(r *http.Request, resp *http.Response, err error, duration time.Duration) {
+	if c.opts.Logger == nil {
+		return
+	}
+	entry := RequestLog{Method: r.Method, Path: r.URL.Path, Duration: duration, Err: err}
+	if resp != nil {
+		entry.StatusCode = resp.StatusCode
+		entry.Warning = warning(resp)
+	}
+	c.opts.Logger.LogRequest(r.Context(), entry)
+}
+
+var authorizationHeader = regexp.MustCompile(`(?im)^(Authorization:).*$`)
+
+// redactAuthorization hides the credentials in the dumped request
+func redactAuthorization(dump []byte) string {
+	return authorizationHeader.ReplaceAllString(string(dump), "$1 [REDACTED]\r")
+}
+
+// warning returns the warning sent by the server in the Notion-Warning or Warning response header
+func warning(resp *http.Response) string {
+	if w := resp.Header.Get("Notion-Warning"); w != "" {
+		return w
+	}
+	return resp.Header.Get("Warning")
 }
 
-func (c *Client) decode(resp *http.Response, v interface{}) error {
-	err := json.NewDecoder(resp.Body).Decode(v)
+func (c *Client) warn(r *http.Request, resp *http.Response) {
+	w := warning(resp)
+	switch {
+	case w == "":
+	case c.opts.OnWarning != nil:
+		c.opts.OnWarning(r.Method, r.URL.Path, w)
+	case c.opts.Logger != nil:
+		// already passed on in RequestLog.Warning
+	case c.opts.Trace:
+		c.tracer.Printf("Warning for %s %s: %s", r.Method, r.URL.Path, w)
+	default:
+		if _, seen := c.warned.LoadOrStore(r.Method+" "+r.URL.Path, true); !seen {
+			c.stderr.Printf("Warning for %s %s: %s", r.Method, r.URL.Path, w)
+		}
+	}
+}
+
+// parseRetryAfter parses the Retry-After header value given either in seconds or as an HTTP-date
+//
+// It returns 0 if the value can't be parsed or the date is not after now.
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	if seconds, err := strconv.Atoi(v); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(v); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
+
+// parseRemaining parses the X-RateLimit-Remaining header value, it returns -1 and false if it's missing or malformed
+func parseRemaining(v string) (int, bool) {
+	remaining, err := strconv.Atoi(v)
+	if err != nil || remaining < 0 {
+		return -1, false
+	}
+	return remaining, true
+}
+
+// decode reads the whole response body and decodes it into v, the body is returned to help debugging a failure
+//
+// The numbers decoded into an interface{}, e.g. a failure read with ApplicationError.Failure, become a json.Number
+// rather than a float64, so the large integers keep their precision; the numbers decoded into float64 fields are not
+// affected. In the strict mode the fields unknown to v are rejected, see checkFields. An empty body of a successful
+// response, e.g. 204 No Content, leaves v untouched.
+func (c *Client) decode(resp *http.Response, v interface{}, strict bool) ([]byte, error) {
+	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return err
+		return body, err
+	}
+	if len(bytes.TrimSpace(body)) == 0 && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return body, nil
+	}
+	if strict {
+		if err := checkFields(body, reflect.TypeOf(v)); err != nil {
+			return body, err
+		}
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	return body, dec.Decode(v)
+}
+
+// checkFields rejects the JSON object fields which don't match a field of the struct type t, at any depth
+//
+// Unlike json.Decoder.DisallowUnknownFields it looks into the types with their own UnmarshalJSON, which e.g. fill in
+// defaults after decoding into an alias type. The structs without any json tags, e.g. a wrapper decoding into one of
+// its fields depending on the content, are not checked.
+func checkFields(data []byte, t reflect.Type) error {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil {
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		fields := map[string]reflect.Type{}
+		if !jsonFields(t, fields) {
+			return nil
+		}
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(data, &object); err != nil {
+			return nil
+		}
+		for key, value := range object {
+			ft, ok := fields[strings.ToLower(key)]
+			if !ok {
+				return fmt.Errorf("json: unknown field %q", key)
+			}
+			if err := checkFields(value, ft); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil
+		}
+		for _, item := range items {
+			if err := checkFields(item, t.Elem()); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		var values map[string]json.RawMessage
+		if err := json.Unmarshal(data, &values); err != nil {
+			return nil
+		}
+		for _, value := range values {
+			if err := checkFields(value, t.Elem()); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
+
+// jsonFields collects the JSON names of the struct fields, lower-cased as the names are matched case-insensitively,
+// the fields of the embedded structs included; it reports whether any of the fields has a json tag
+func jsonFields(t reflect.Type, fields map[string]reflect.Type) bool {
+	tagged := false
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, hasTag := f.Tag.Lookup("json")
+		name := strings.Split(tag, ",")[0]
+		tagged = tagged || hasTag
+		if name == "-" && tag == "-" {
+			continue
+		}
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			tagged = jsonFields(ft, fields) || tagged
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[strings.ToLower(name)] = f.Type
+	}
+	return tagged
+}
+
+// joinURL appends the path to the root URL with a single slash between them, whether root ends or path starts with a
+// slash or not
+func joinURL(root, path string) string {
+	if path == "" {
+		return root
+	}
+	return strings.TrimSuffix(root, "/") + "/" + strings.TrimPrefix(path, "/")
+}
+
+// maxBodySnippet is the length of the response body quoted in the decoding errors
+const maxBodySnippet = 200
+
+func decodeError(kind string, body []byte, err error) LocalError {
+	snippet := string(body)
+	if len(snippet) > maxBodySnippet {
+		snippet = snippet[:maxBodySnippet] + "..."
+	}
+	return LocalError{Reason: fmt.Sprintf("can't decode %s response %q", kind, snippet), Inner: err, Body: body}
+}
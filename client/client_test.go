@@ -4,37 +4,47 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"log"
 	"net/http"
+	"net/url"
 	"reflect"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"notion-go/notiontest"
 )
 
 // RequestToResponse is a function which given the request produces a response or an error
-type RequestToResponse func(req *http.Request) (*http.Response, error)
-
-// RoundTrip method to implement http.RoundTripper interface
-func (f RequestToResponse) RoundTrip(req *http.Request) (*http.Response, error) {
-	return f(req)
-}
+type RequestToResponse = notiontest.RequestToResponse
 
 // Create a mock http.Client which instead of making an http call will use provided function to provide a response
 func RequestCapturingMockHttpClient(f RequestToResponse) (*http.Client, *http.Request) {
-	var capture http.Request
-	return &http.Client{
-		Transport: RequestToResponse(func(req *http.Request) (*http.Response, error) {
-			capture = *req.Clone(req.Context())
-			return f(req)
-		}),
-	}, &capture
+	return notiontest.NewCapturingClient(f)
 }
 
 type body struct {
 	Body string `json:"body,omitempty"`
 }
 
+type rawBody struct {
+	contentType string
+	data        string
+}
+
+func (b rawBody) ContentType() string {
+	return b.contentType
+}
+
+func (b rawBody) Encode() (io.Reader, error) {
+	return bytes.NewBufferString(b.data), nil
+}
+
 type success struct {
 	Success string `json:"success,omitempty"`
 }
@@ -81,6 +91,26 @@ func TestClient_Do(t *testing.T) {
 			wantTargetFailure: failure{Failure: "internal server error"},
 			wantErrMsg:        "application error: &{internal server error}",
 		},
+		{
+			name: "should decode a created response into the target",
+			response: func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: 201,
+					Body:       ioutil.NopCloser(bytes.NewBufferString(`{"success":"created"}`)),
+				}, nil
+			},
+			wantTargetSuccess: success{Success: "created"},
+		},
+		{
+			name: "should return an ApplicationError on a redirect without decoding the body",
+			response: func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: 301,
+					Body:       ioutil.NopCloser(bytes.NewBufferString(`<html>Moved</html>`)),
+				}, nil
+			},
+			wantErrMsg: `application error: unexpected redirect 301 to ""`,
+		},
 		{
 			name: "should fail with LocalError when request can't be created",
 			args: args{
@@ -96,7 +126,7 @@ func TestClient_Do(t *testing.T) {
 					Body:       ioutil.NopCloser(bytes.NewBufferString(`#yolo`)),
 				}, nil
 			},
-			wantErrMsg: `local error: can't decode successful response: invalid character '#' looking for beginning of value`,
+			wantErrMsg: `local error: can't decode successful response "#yolo": invalid character '#' looking for beginning of value`,
 		},
 		{
 			name: "should fail with LocalError when failure response can't be decoded",
@@ -106,7 +136,7 @@ func TestClient_Do(t *testing.T) {
 					Body:       ioutil.NopCloser(bytes.NewBufferString(`#yolo`)),
 				}, nil
 			},
-			wantErrMsg: `local error: can't decode failure response: invalid character '#' looking for beginning of value`,
+			wantErrMsg: `local error: can't decode failure response "#yolo": invalid character '#' looking for beginning of value`,
 		},
 		{
 			name: "should fail with TransportError when connection fails",
@@ -152,6 +182,32 @@ func TestClient_Do(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "should send the body using a custom encoder",
+			response: func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: 200,
+					Body:       ioutil.NopCloser(bytes.NewBufferString(`{"success":"yes"}`)),
+				}, nil
+			},
+			args: args{
+				method: http.MethodPost,
+				path:   "/upload",
+				body:   rawBody{contentType: "text/plain", data: "hello"},
+			},
+			wantTargetSuccess: success{Success: "yes"},
+			wantRequest: func(t *testing.T, r *http.Request) {
+				wantContentType := "text/plain"
+				if got := r.Header.Get("Content-Type"); got != wantContentType {
+					t.Errorf("Content-Type = %s, want %s", got, wantContentType)
+				}
+				wantBody := "hello"
+				gotBody, _ := ioutil.ReadAll(r.Body)
+				if string(gotBody) != wantBody {
+					t.Errorf("r.Body = %s, want %s", gotBody, wantBody)
+				}
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -196,3 +252,1018 @@ func TestClient_Do(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_Do_DecodeErrorBody(t *testing.T) {
+	longBody := strings.Repeat("x", 300)
+	tests := []struct {
+		name        string
+		body        string
+		wantSnippet string
+	}{
+		{
+			name:        "should quote the body",
+			body:        "#yolo",
+			wantSnippet: `"#yolo"`,
+		},
+		{
+			name:        "should truncate a long body",
+			body:        longBody,
+			wantSnippet: `"` + longBody[:200] + `..."`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient, _ := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: 500,
+					Body:       ioutil.NopCloser(bytes.NewBufferString(tt.body)),
+				}, nil
+			})
+			c := New(httpClient, Options{})
+
+			err := c.Do(context.Background(), http.MethodGet, "/foo", nil, nil, &success{}, &failure{})
+
+			var localErr LocalError
+			if !errors.As(err, &localErr) {
+				t.Fatalf("Do() error = %v, want LocalError", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantSnippet) {
+				t.Errorf("Do() error = %v, want it to contain %s", err, tt.wantSnippet)
+			}
+			if string(localErr.Body) != tt.body {
+				t.Errorf("Body = %q, want %q", localErr.Body, tt.body)
+			}
+		})
+	}
+}
+
+func TestClient_Do_EmptySuccess(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+	}{
+		{name: "should accept no content", statusCode: 204, body: ""},
+		{name: "should accept an empty ok", statusCode: 200, body: ""},
+		{name: "should accept a blank ok", statusCode: 200, body: " \n"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient, _ := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: tt.statusCode,
+					Body:       ioutil.NopCloser(bytes.NewBufferString(tt.body)),
+				}, nil
+			})
+			for _, strict := range []bool{false, true} {
+				c := New(httpClient, Options{StrictDecode: strict})
+
+				got := &success{}
+				if err := c.Do(context.Background(), http.MethodDelete, "/foo", nil, nil, got, &failure{}); err != nil {
+					t.Fatalf("Do() error = %v, wantErr <nil>", err)
+				}
+				if !reflect.DeepEqual(got, &success{}) {
+					t.Errorf("Do() success = %v, want zero value", got)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_Do_URL(t *testing.T) {
+	tests := []struct {
+		name string
+		root string
+		path string
+	}{
+		{name: "should join root and path", root: "https://api.example.com/v1", path: "/pages"},
+		{name: "should drop the trailing slash of root", root: "https://api.example.com/v1/", path: "/pages"},
+		{name: "should add the leading slash of path", root: "https://api.example.com/v1", path: "pages"},
+		{name: "should use a single slash", root: "https://api.example.com/v1/", path: "pages"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient, capturedRequest := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: 200,
+					Body:       ioutil.NopCloser(bytes.NewBufferString(`{"success":"yes"}`)),
+				}, nil
+			})
+			c := New(httpClient, Options{RootURL: tt.root})
+
+			if err := c.Do(context.Background(), http.MethodGet, tt.path, nil, nil, &success{}, &failure{}); err != nil {
+				t.Fatalf("Do() error = %v, wantErr <nil>", err)
+			}
+			want := "https://api.example.com/v1/pages"
+			if got := capturedRequest.URL.String(); got != want {
+				t.Errorf("URL = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestClient_DoValues(t *testing.T) {
+	httpClient, capturedRequest := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"success":"yes"}`)),
+		}, nil
+	})
+	c := New(httpClient, Options{RootURL: "https://api.example.com"})
+
+	query := url.Values{"filter_properties": []string{"abc", "def"}}
+	if err := c.DoValues(context.Background(), http.MethodGet, "/foo", query, nil, &success{}, &failure{}); err != nil {
+		t.Fatalf("DoValues() error = %v, wantErr <nil>", err)
+	}
+
+	want := "filter_properties=abc&filter_properties=def"
+	if got := capturedRequest.URL.RawQuery; got != want {
+		t.Errorf("query = %s, want %s", got, want)
+	}
+}
+
+func TestClient_Do_NotModified(t *testing.T) {
+	httpClient, capturedRequest := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 304,
+			Header:     http.Header{"Etag": []string{`"v1"`}},
+			Body:       ioutil.NopCloser(bytes.NewBufferString("")),
+		}, nil
+	})
+	c := New(httpClient, Options{AddHeaders: map[string]string{"Authorization": "Bearer token"}})
+
+	ctx := WithRequestHeader(context.Background(), "If-None-Match", `"v1"`)
+	gotErr := c.Do(ctx, http.MethodGet, "/foo", nil, nil, &success{}, &failure{})
+
+	if !errors.Is(gotErr, ErrNotModified) {
+		t.Errorf("Do() error = %v, want %v", gotErr, ErrNotModified)
+	}
+	if got := capturedRequest.Header.Get("If-None-Match"); got != `"v1"` {
+		t.Errorf("If-None-Match = %q, want %q", got, `"v1"`)
+	}
+	if got := capturedRequest.Header.Get("Authorization"); got != "Bearer token" {
+		t.Errorf("Authorization = %q, want the default header kept", got)
+	}
+}
+
+func TestClient_Do_RequestID(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		id     string
+		want   string
+	}{
+		{
+			name:   "should send the request ID from the context",
+			header: "X-Correlation-Id",
+			id:     "4c1d6a3e-8f2b-4a9c-b1e7-0d5f6a7b8c9d",
+			want:   "4c1d6a3e-8f2b-4a9c-b1e7-0d5f6a7b8c9d",
+		},
+		{
+			name: "should not send the request ID without the header configured",
+			id:   "4c1d6a3e-8f2b-4a9c-b1e7-0d5f6a7b8c9d",
+		},
+		{
+			name:   "should not send an empty header without the request ID",
+			header: "X-Correlation-Id",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient, capturedRequest := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: 200,
+					Body:       ioutil.NopCloser(bytes.NewBufferString(`{"success":"yes"}`)),
+				}, nil
+			})
+			c := New(httpClient, Options{RequestIDHeader: tt.header})
+
+			ctx := context.Background()
+			if tt.id != "" {
+				ctx = WithRequestID(ctx, tt.id)
+			}
+			if err := c.Do(ctx, http.MethodGet, "/foo", nil, nil, &success{}, &failure{}); err != nil {
+				t.Fatalf("Do() error = %v, wantErr <nil>", err)
+			}
+
+			if got := capturedRequest.Header.Get("X-Correlation-Id"); got != tt.want {
+				t.Errorf("X-Correlation-Id = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_Do_DecodeNumber(t *testing.T) {
+	t.Run("should keep a large integer property value", func(t *testing.T) {
+		httpClient, _ := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: 200,
+				Body: ioutil.NopCloser(bytes.NewBufferString(
+					`{"object": "page", "properties": {"Count": {"type": "number", "number": 9007199254740993}}}`,
+				)),
+			}, nil
+		})
+		c := New(httpClient, Options{})
+
+		var page struct {
+			Properties map[string]map[string]interface{} `json:"properties"`
+		}
+		if err := c.Do(context.Background(), http.MethodGet, "/foo", nil, nil, &page, &failure{}); err != nil {
+			t.Fatalf("Do() error = %v, wantErr <nil>", err)
+		}
+		if got := page.Properties["Count"]["number"]; got != json.Number("9007199254740993") {
+			t.Errorf("number = %#v, want %#v", got, json.Number("9007199254740993"))
+		}
+	})
+
+	t.Run("should keep a large integer in a failure", func(t *testing.T) {
+		httpClient, _ := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: 400,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"code": "validation_error", "value": 9007199254740993}`)),
+			}, nil
+		})
+		c := New(httpClient, Options{})
+
+		var failure interface{}
+		err := c.Do(context.Background(), http.MethodGet, "/foo", nil, nil, &success{}, &failure)
+
+		var appErr ApplicationError
+		if !errors.As(err, &appErr) {
+			t.Fatalf("Do() error = %v, want ApplicationError", err)
+		}
+		got, ok := (*appErr.Failure().(*interface{})).(map[string]interface{})
+		if !ok {
+			t.Fatalf("Failure() = %#v, want a map", appErr.Failure())
+		}
+		if got["value"] != json.Number("9007199254740993") {
+			t.Errorf("value = %#v, want %#v", got["value"], json.Number("9007199254740993"))
+		}
+	})
+}
+
+func TestClient_Do_StrictDecode(t *testing.T) {
+	tests := []struct {
+		name       string
+		strict     bool
+		statusCode int
+		body       string
+		wantErrMsg string
+	}{
+		{
+			name:       "should ignore an unknown field by default",
+			statusCode: 200,
+			body:       `{"success":"yes","extra":1}`,
+		},
+		{
+			name:       "should reject an unknown field in the strict mode",
+			strict:     true,
+			statusCode: 200,
+			body:       `{"success":"yes","extra":1}`,
+			wantErrMsg: `local error: can't decode successful response "{\"success\":\"yes\",\"extra\":1}": json: unknown field "extra"`,
+		},
+		{
+			name:       "should decode an error response leniently in the strict mode",
+			strict:     true,
+			statusCode: 400,
+			body:       `{"failure":"no","extra":1}`,
+			wantErrMsg: "application error: &{no}",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient, _ := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: tt.statusCode,
+					Body:       ioutil.NopCloser(bytes.NewBufferString(tt.body)),
+				}, nil
+			})
+			c := New(httpClient, Options{StrictDecode: tt.strict})
+
+			gotErr := c.Do(context.Background(), http.MethodGet, "/foo", nil, nil, &success{}, &failure{})
+
+			if tt.wantErrMsg != "" {
+				if gotErr == nil || gotErr.Error() != tt.wantErrMsg {
+					t.Errorf("Do() error = %v, wantErr %v", gotErr, tt.wantErrMsg)
+				}
+				return
+			}
+			if gotErr != nil {
+				t.Errorf("Do() error = %v, wantErr <nil>", gotErr)
+			}
+		})
+	}
+}
+
+// successList fills in the empty results in its own UnmarshalJSON, which json.Decoder.DisallowUnknownFields doesn't
+// reach into
+type successList struct {
+	Results []success `json:"results"`
+}
+
+func (l *successList) UnmarshalJSON(data []byte) error {
+	type list successList
+	if err := json.Unmarshal(data, (*list)(l)); err != nil {
+		return err
+	}
+	if l.Results == nil {
+		l.Results = []success{}
+	}
+	return nil
+}
+
+func TestClient_Do_StrictDecodeCustomUnmarshal(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		want       *successList
+		wantErrMsg string
+	}{
+		{
+			name: "should decode known fields",
+			body: `{"results":null}`,
+			want: &successList{Results: []success{}},
+		},
+		{
+			name:       "should reject an unknown field of the list",
+			body:       `{"results":[],"extra":1}`,
+			wantErrMsg: `json: unknown field "extra"`,
+		},
+		{
+			name:       "should reject an unknown field of an item",
+			body:       `{"results":[{"success":"yes","extra":1}]}`,
+			wantErrMsg: `json: unknown field "extra"`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient, _ := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBufferString(tt.body))}, nil
+			})
+			c := New(httpClient, Options{StrictDecode: true})
+
+			got := &successList{}
+			gotErr := c.Do(context.Background(), http.MethodGet, "/foo", nil, nil, got, &failure{})
+
+			if tt.wantErrMsg != "" {
+				if gotErr == nil || !strings.Contains(gotErr.Error(), tt.wantErrMsg) {
+					t.Errorf("Do() error = %v, wantErr %v", gotErr, tt.wantErrMsg)
+				}
+				return
+			}
+			if gotErr != nil {
+				t.Fatalf("Do() error = %v, wantErr <nil>", gotErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Do() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_Do_Retry(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		maxRetries   int
+		statusCodes  []int
+		wantAttempts int
+		wantErrMsg   string
+	}{
+		{
+			name:         "should retry a rate-limited request",
+			maxRetries:   2,
+			statusCodes:  []int{429, 200},
+			wantAttempts: 2,
+		},
+		{
+			name:         "should give up after max retries",
+			maxRetries:   2,
+			statusCodes:  []int{503, 502, 429, 200},
+			wantAttempts: 3,
+			wantErrMsg:   "application error: &{rate limited}",
+		},
+		{
+			name:         "should not retry when retries are disabled",
+			statusCodes:  []int{429, 200},
+			wantAttempts: 1,
+			wantErrMsg:   "application error: &{rate limited}",
+		},
+		{
+			name:         "should retry a server error",
+			maxRetries:   2,
+			statusCodes:  []int{500, 200},
+			wantAttempts: 2,
+		},
+		{
+			name:         "should not retry a client error",
+			maxRetries:   2,
+			statusCodes:  []int{400, 200},
+			wantAttempts: 1,
+			wantErrMsg:   "application error: &{rate limited}",
+		},
+		{
+			name:         "should not retry a server error of a POST",
+			method:       http.MethodPost,
+			maxRetries:   2,
+			statusCodes:  []int{500, 200},
+			wantAttempts: 1,
+			wantErrMsg:   "application error: &{rate limited}",
+		},
+		{
+			name:         "should retry a rate-limited POST",
+			method:       http.MethodPost,
+			maxRetries:   2,
+			statusCodes:  []int{429, 200},
+			wantAttempts: 2,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			httpClient, _ := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+				statusCode := tt.statusCodes[attempts]
+				attempts++
+				if statusCode != 200 {
+					return &http.Response{
+						StatusCode: statusCode,
+						Header:     http.Header{"Retry-After": []string{"0"}},
+						Body:       ioutil.NopCloser(bytes.NewBufferString(`{"failure":"rate limited"}`)),
+					}, nil
+				}
+				return &http.Response{
+					StatusCode: statusCode,
+					Body:       ioutil.NopCloser(bytes.NewBufferString(`{"success":"yes"}`)),
+				}, nil
+			})
+			c := New(httpClient, Options{MaxRetries: tt.maxRetries, BackoffBase: time.Millisecond})
+
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			err := c.Do(context.Background(), method, "/foo", nil, nil, &success{}, &failure{})
+
+			if tt.wantErrMsg != "" {
+				if err == nil {
+					err = fmt.Errorf("no error")
+				}
+				if !strings.Contains(err.Error(), tt.wantErrMsg) {
+					t.Errorf("Do() error = %v, wantErr %v", err, tt.wantErrMsg)
+				}
+			} else if err != nil {
+				t.Errorf("Do() error = %v, wantErr <nil>", err)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestClient_Do_RetryWithoutRetryAfter(t *testing.T) {
+	attempts := 0
+	httpClient, _ := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		if attempts <= 2 {
+			return &http.Response{
+				StatusCode: 500,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"failure":"internal error"}`)),
+			}, nil
+		}
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"success":"yes"}`)),
+		}, nil
+	})
+	c := New(httpClient, Options{MaxRetries: 2, BackoffBase: 20 * time.Millisecond})
+
+	start := time.Now()
+	if err := c.Do(context.Background(), http.MethodGet, "/foo", nil, nil, &success{}, &failure{}); err != nil {
+		t.Fatalf("Do() error = %v, wantErr <nil>", err)
+	}
+	elapsed := time.Since(start)
+
+	if attempts != 3 {
+		t.Errorf("attempts = %d, want 3", attempts)
+	}
+	// the backoff waits at least half of 20ms and then of 40ms
+	if elapsed < 30*time.Millisecond {
+		t.Errorf("elapsed = %v, want at least 30ms of backoff", elapsed)
+	}
+}
+
+func TestClient_Do_RetryTransportError(t *testing.T) {
+	attempts := 0
+	httpClient, _ := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		if attempts <= 2 {
+			return nil, errors.New("connection reset by peer")
+		}
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"success":"yes"}`)),
+		}, nil
+	})
+	c := New(httpClient, Options{MaxRetries: 3, BackoffBase: time.Millisecond})
+
+	err := c.Do(context.Background(), http.MethodGet, "/foo", nil, nil, &success{}, &failure{})
+
+	if err != nil {
+		t.Errorf("Do() error = %v, wantErr <nil>", err)
+	}
+	if attempts != 3 {
+		t.Errorf("attempts = %d, want 3", attempts)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	base := 100 * time.Millisecond
+	for attempt, want := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond} {
+		for i := 0; i < 100; i++ {
+			if got := backoff(base, attempt); got < want/2 || got > want {
+				t.Fatalf("backoff(%v, %d) = %v, want between %v and %v", base, attempt, got, want/2, want)
+			}
+		}
+	}
+}
+
+func TestClient_Do_RetryCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var attempts int32
+	httpClient := &http.Client{Transport: RequestToResponse(func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&attempts, 1)
+		time.AfterFunc(10*time.Millisecond, cancel)
+		return &http.Response{
+			StatusCode: 429,
+			Header:     http.Header{"Retry-After": []string{"60"}},
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"failure":"rate limited"}`)),
+		}, nil
+	})}
+	c := New(httpClient, Options{MaxRetries: 2})
+
+	start := time.Now()
+	err := c.Do(ctx, http.MethodGet, "/foo", nil, nil, &success{}, &failure{})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Do() error = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Do() took %v, want it to return on cancellation", elapsed)
+	}
+	if got := atomic.LoadInt32(&attempts); got != 1 {
+		t.Errorf("attempts = %d, want 1", got)
+	}
+}
+
+func TestClient_Do_Timeout(t *testing.T) {
+	tests := []struct {
+		name         string
+		timeout      time.Duration
+		ctxTimeout   time.Duration
+		wantDeadline time.Duration
+	}{
+		{
+			name:         "should time out a hung request",
+			timeout:      20 * time.Millisecond,
+			wantDeadline: 20 * time.Millisecond,
+		},
+		{
+			name:         "should keep a shorter caller deadline",
+			timeout:      time.Minute,
+			ctxTimeout:   20 * time.Millisecond,
+			wantDeadline: 20 * time.Millisecond,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient := &http.Client{Transport: RequestToResponse(func(req *http.Request) (*http.Response, error) {
+				<-req.Context().Done()
+				return nil, req.Context().Err()
+			})}
+			c := New(httpClient, Options{Timeout: tt.timeout})
+			ctx := context.Background()
+			if tt.ctxTimeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.ctxTimeout)
+				defer cancel()
+			}
+
+			start := time.Now()
+			err := c.Do(ctx, http.MethodGet, "/foo", nil, nil, &success{}, &failure{})
+
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("Do() error = %v, want context.DeadlineExceeded", err)
+			}
+			if elapsed := time.Since(start); elapsed > tt.wantDeadline+time.Second {
+				t.Errorf("Do() took %v, want about %v", elapsed, tt.wantDeadline)
+			}
+		})
+	}
+}
+
+func TestClient_Do_Middleware(t *testing.T) {
+	var calls []string
+	record := func(name string) func(http.RoundTripper) http.RoundTripper {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return RequestToResponse(func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, name+" before")
+				resp, err := next.RoundTrip(req)
+				calls = append(calls, name+" after")
+				return resp, err
+			})
+		}
+	}
+	transport := RequestToResponse(func(req *http.Request) (*http.Response, error) {
+		calls = append(calls, "transport")
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"success":"yes"}`)),
+		}, nil
+	})
+	httpClient := &http.Client{Transport: transport}
+	c := New(httpClient, Options{Middleware: []func(http.RoundTripper) http.RoundTripper{record("outer"), record("inner")}})
+
+	if err := c.Do(context.Background(), http.MethodGet, "/foo", nil, nil, &success{}, &failure{}); err != nil {
+		t.Fatalf("Do() error = %v, wantErr <nil>", err)
+	}
+
+	wantCalls := []string{"outer before", "inner before", "transport", "inner after", "outer after"}
+	if !reflect.DeepEqual(calls, wantCalls) {
+		t.Errorf("calls = %v, want %v", calls, wantCalls)
+	}
+	if _, ok := httpClient.Transport.(RequestToResponse); !ok {
+		t.Errorf("the original http client transport was replaced")
+	}
+}
+
+func TestClient_Do_Trace(t *testing.T) {
+	httpClient, _ := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 201,
+			Status:     "201 Created",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"success":"created"}`)),
+		}, nil
+	})
+	var trace bytes.Buffer
+	c := New(httpClient, Options{
+		RootURL:     "https://api.example.com",
+		AddHeaders:  map[string]string{"Authorization": "Bearer secret-token"},
+		Trace:       true,
+		TraceWriter: &trace,
+	})
+
+	err := c.Do(context.Background(), http.MethodPost, "/foo", nil, map[string]string{"name": "bar"}, &success{}, &failure{})
+	if err != nil {
+		t.Fatalf("Do() error = %v, wantErr <nil>", err)
+	}
+
+	got := trace.String()
+	for _, want := range []string{"POST /foo", "Host: api.example.com", `{"name":"bar"}`, "201 Created", `{"success":"created"}`, "Authorization: [REDACTED]"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("trace = %s, want it to contain %q", got, want)
+		}
+	}
+	if strings.Contains(got, "secret-token") {
+		t.Errorf("trace = %s, want the token redacted", got)
+	}
+}
+
+type recordingLogger struct {
+	entries []RequestLog
+}
+
+func (l *recordingLogger) LogRequest(_ context.Context, entry RequestLog) {
+	l.entries = append(l.entries, entry)
+}
+
+func TestClient_Do_Logger(t *testing.T) {
+	httpClient, _ := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 404,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"failure":"not found"}`)),
+		}, nil
+	})
+	logger := &recordingLogger{}
+	c := New(httpClient, Options{Logger: logger})
+
+	_ = c.Do(context.Background(), http.MethodGet, "/foo", nil, nil, &success{}, &failure{})
+
+	if len(logger.entries) != 1 {
+		t.Fatalf("entries = %+v, want a single entry", logger.entries)
+	}
+	got := logger.entries[0]
+	if got.Method != http.MethodGet || got.Path != "/foo" || got.StatusCode != 404 || got.Err != nil {
+		t.Errorf("entry = %+v, want GET /foo with status code 404", got)
+	}
+}
+
+func TestClient_Do_RateLimited(t *testing.T) {
+	httpClient, _ := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 429,
+			Header:     http.Header{"Retry-After": []string{"7"}},
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"failure":"rate limited"}`)),
+		}, nil
+	})
+	c := New(httpClient, Options{})
+
+	err := c.Do(context.Background(), http.MethodGet, "/foo", nil, nil, &success{}, &failure{})
+
+	var appErr ApplicationError
+	if !errors.As(err, &appErr) {
+		t.Fatalf("Do() error = %v, want ApplicationError", err)
+	}
+	if !appErr.IsRateLimited() {
+		t.Errorf("IsRateLimited() = false, want true")
+	}
+	if want := 7 * time.Second; appErr.RetryAfter != want {
+		t.Errorf("RetryAfter = %v, want %v", appErr.RetryAfter, want)
+	}
+}
+
+func TestClient_Do_Quota(t *testing.T) {
+	tests := []struct {
+		name          string
+		statusCode    int
+		header        http.Header
+		wantQuota     []string
+		wantRemaining int
+	}{
+		{
+			name:          "should report the remaining quota",
+			statusCode:    200,
+			header:        http.Header{"X-Ratelimit-Remaining": []string{"42"}},
+			wantQuota:     []string{"GET /foo: 42"},
+			wantRemaining: 42,
+		},
+		{
+			name:          "should report the remaining quota on a rate-limited response",
+			statusCode:    429,
+			header:        http.Header{"X-Ratelimit-Remaining": []string{"0"}},
+			wantQuota:     []string{"GET /foo: 0"},
+			wantRemaining: 0,
+		},
+		{
+			name:          "should not report a missing quota",
+			statusCode:    429,
+			wantRemaining: -1,
+		},
+		{
+			name:          "should not report a malformed quota",
+			statusCode:    429,
+			header:        http.Header{"X-Ratelimit-Remaining": []string{"plenty"}},
+			wantRemaining: -1,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient, _ := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: tt.statusCode,
+					Header:     tt.header,
+					Body:       ioutil.NopCloser(bytes.NewBufferString(`{"failure":"rate limited"}`)),
+				}, nil
+			})
+			var gotQuota []string
+			c := New(httpClient, Options{
+				OnQuota: func(method, path string, remaining int) {
+					gotQuota = append(gotQuota, fmt.Sprintf("%s %s: %d", method, path, remaining))
+				},
+			})
+
+			err := c.Do(context.Background(), http.MethodGet, "/foo", nil, nil, &success{}, &failure{})
+
+			if !reflect.DeepEqual(gotQuota, tt.wantQuota) {
+				t.Errorf("OnQuota() calls = %v, want %v", gotQuota, tt.wantQuota)
+			}
+			if tt.statusCode == 200 {
+				return
+			}
+			var appErr ApplicationError
+			if !errors.As(err, &appErr) {
+				t.Fatalf("Do() error = %v, want ApplicationError", err)
+			}
+			if appErr.Remaining != tt.wantRemaining {
+				t.Errorf("Remaining = %d, want %d", appErr.Remaining, tt.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2021, 5, 20, 9, 19, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{
+			name:  "should parse seconds",
+			value: "120",
+			want:  2 * time.Minute,
+		},
+		{
+			name:  "should parse an HTTP-date",
+			value: "Thu, 20 May 2021 09:19:30 GMT",
+			want:  30 * time.Second,
+		},
+		{
+			name:  "should ignore an HTTP-date in the past",
+			value: "Thu, 20 May 2021 09:18:00 GMT",
+			want:  0,
+		},
+		{
+			name:  "should ignore a missing value",
+			value: "",
+			want:  0,
+		},
+		{
+			name:  "should ignore a malformed value",
+			value: "soon",
+			want:  0,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRetryAfter(tt.value, now); got != tt.want {
+				t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_Do_Warning(t *testing.T) {
+	tests := []struct {
+		name        string
+		header      http.Header
+		wantWarning string
+	}{
+		{
+			name:        "should report the Notion-Warning header",
+			header:      http.Header{"Notion-Warning": []string{"This endpoint is deprecated, use /v1/search"}},
+			wantWarning: "GET /foo: This endpoint is deprecated, use /v1/search",
+		},
+		{
+			name:        "should report the Warning header",
+			header:      http.Header{"Warning": []string{`299 - "Deprecated API"`}},
+			wantWarning: `GET /foo: 299 - "Deprecated API"`,
+		},
+		{
+			name: "should not report anything without a warning header",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient, _ := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: 200,
+					Header:     tt.header,
+					Body:       ioutil.NopCloser(bytes.NewBufferString(`{"success":"yes"}`)),
+				}, nil
+			})
+			var gotWarning string
+			c := New(httpClient, Options{
+				OnWarning: func(method, path, warning string) {
+					gotWarning = fmt.Sprintf("%s %s: %s", method, path, warning)
+				},
+			})
+
+			if err := c.Do(context.Background(), http.MethodGet, "/foo", nil, nil, &success{}, &failure{}); err != nil {
+				t.Fatalf("Do() error = %v, wantErr <nil>", err)
+			}
+			if gotWarning != tt.wantWarning {
+				t.Errorf("warning = %q, want %q", gotWarning, tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestClient_Do_WarningWithoutOnWarning(t *testing.T) {
+	httpClient, _ := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 200,
+			Header:     http.Header{"Notion-Warning": []string{"deprecated"}},
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"success":"yes"}`)),
+		}, nil
+	})
+	doTwice := func(t *testing.T, c *Client) {
+		for i := 0; i < 2; i++ {
+			if err := c.Do(context.Background(), http.MethodGet, "/foo", nil, nil, &success{}, &failure{}); err != nil {
+				t.Fatalf("Do() error = %v, wantErr <nil>", err)
+			}
+		}
+	}
+
+	t.Run("should pass the warning to the logger", func(t *testing.T) {
+		logger := &recordingLogger{}
+		c := New(httpClient, Options{Logger: logger})
+		stderr := &bytes.Buffer{}
+		c.stderr = log.New(stderr, "", 0)
+
+		doTwice(t, c)
+
+		if len(logger.entries) != 2 || logger.entries[0].Warning != "deprecated" {
+			t.Errorf("entries = %+v, want two entries with the warning", logger.entries)
+		}
+		if stderr.Len() != 0 {
+			t.Errorf("stderr = %q, want nothing", stderr.String())
+		}
+	})
+
+	t.Run("should write the warning to the trace", func(t *testing.T) {
+		trace := &bytes.Buffer{}
+		c := New(httpClient, Options{Trace: true, TraceWriter: trace})
+		stderr := &bytes.Buffer{}
+		c.stderr = log.New(stderr, "", 0)
+
+		doTwice(t, c)
+
+		if got := strings.Count(trace.String(), "Warning for GET /foo: deprecated"); got != 2 {
+			t.Errorf("trace has %d warnings, want 2", got)
+		}
+		if stderr.Len() != 0 {
+			t.Errorf("stderr = %q, want nothing", stderr.String())
+		}
+	})
+
+	t.Run("should log the warning to stderr once per path", func(t *testing.T) {
+		c := New(httpClient, Options{})
+		stderr := &bytes.Buffer{}
+		c.stderr = log.New(stderr, "", 0)
+
+		doTwice(t, c)
+
+		if got, want := stderr.String(), "Warning for GET /foo: deprecated\n"; got != want {
+			t.Errorf("stderr = %q, want %q", got, want)
+		}
+	})
+}
+
+func TestClient_Do_Hedge(t *testing.T) {
+	var attempts int32
+	slowCancelled := make(chan struct{})
+	httpClient := &http.Client{Transport: RequestToResponse(func(req *http.Request) (*http.Response, error) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			<-req.Context().Done()
+			close(slowCancelled)
+			return nil, req.Context().Err()
+		}
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"success":"hedge"}`)),
+		}, nil
+	})}
+	c := New(httpClient, Options{HedgeDelay: 10 * time.Millisecond})
+
+	got := success{}
+	if err := c.Do(context.Background(), http.MethodGet, "/foo", nil, nil, &got, &failure{}); err != nil {
+		t.Fatalf("Do() error = %v, wantErr <nil>", err)
+	}
+
+	if want := (success{Success: "hedge"}); got != want {
+		t.Errorf("Do() targetSuccess = %v, want %v", got, want)
+	}
+	if n := atomic.LoadInt32(&attempts); n != 2 {
+		t.Errorf("attempts = %d, want 2", n)
+	}
+	select {
+	case <-slowCancelled:
+	case <-time.After(time.Second):
+		t.Errorf("the slow request was not cancelled")
+	}
+}
+
+func TestClient_Do_HedgeOnlyGet(t *testing.T) {
+	var attempts int32
+	httpClient, _ := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&attempts, 1)
+		time.Sleep(30 * time.Millisecond)
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"success":"yes"}`)),
+		}, nil
+	})
+	c := New(httpClient, Options{HedgeDelay: time.Millisecond})
+
+	if err := c.Do(context.Background(), http.MethodPatch, "/foo", nil, &body{Body: "x"}, &success{}, &failure{}); err != nil {
+		t.Fatalf("Do() error = %v, wantErr <nil>", err)
+	}
+	if n := atomic.LoadInt32(&attempts); n != 1 {
+		t.Errorf("attempts = %d, want 1", n)
+	}
+}
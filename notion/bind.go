@@ -0,0 +1,151 @@
+package notion
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// MarshalPage converts a struct into page property values
+//
+// The struct fields are mapped to the properties with the `notion:"Property Name"` tag, the fields without the tag are
+// skipped. The property type is derived from the field type:
+//
+//	string    rich_text, or title if the tag has the title option, e.g. `notion:"Name,title"`
+//	bool      checkbox
+//	int, ...  number
+//	float64   number
+//	[]string  multi_select
+func MarshalPage(v interface{}) (map[string]PropertyValue, error) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("notion: MarshalPage expects a struct, got %T", v)
+	}
+	properties := make(map[string]PropertyValue)
+	for i := 0; i < rv.NumField(); i++ {
+		tag, ok := parseTag(rv.Type().Field(i))
+		if !ok {
+			continue
+		}
+		pv, err := marshalField(rv.Field(i), tag)
+		if err != nil {
+			return nil, err
+		}
+		properties[tag.name] = pv
+	}
+	return properties, nil
+}
+
+// BindPage sets the fields of the struct pointed to by v from the page property values
+//
+// The fields are mapped as in MarshalPage. The field with the title option is bound to the page title property
+// regardless of its name. Fields for properties missing on the page are left untouched.
+func BindPage(p *Page, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("notion: BindPage expects a pointer to a struct, got %T", v)
+	}
+	rv = rv.Elem()
+	for i := 0; i < rv.NumField(); i++ {
+		tag, ok := parseTag(rv.Type().Field(i))
+		if !ok {
+			continue
+		}
+		pv, ok := p.Properties[tag.name]
+		if tag.title {
+			pv, ok = p.titleProperty()
+		}
+		if !ok {
+			continue
+		}
+		if err := bindField(rv.Field(i), pv.flatten()); err != nil {
+			return fmt.Errorf("notion: can't bind property %q: %w", tag.name, err)
+		}
+	}
+	return nil
+}
+
+type fieldTag struct {
+	name  string
+	title bool
+}
+
+func parseTag(f reflect.StructField) (fieldTag, bool) {
+	tag, ok := f.Tag.Lookup("notion")
+	if !ok || tag == "-" || f.PkgPath != "" {
+		return fieldTag{}, false
+	}
+	parts := strings.Split(tag, ",")
+	ft := fieldTag{name: parts[0]}
+	for _, option := range parts[1:] {
+		if option == "title" {
+			ft.title = true
+		}
+	}
+	return ft, true
+}
+
+func (p *Page) titleProperty() (PropertyValue, bool) {
+	for _, pv := range p.Properties {
+		if pv.Type == "title" {
+			return pv, true
+		}
+	}
+	return PropertyValue{}, false
+}
+
+func marshalField(f reflect.Value, tag fieldTag) (PropertyValue, error) {
+	switch {
+	case f.Kind() == reflect.String && tag.title:
+		return TitleValue(f.String()), nil
+	case f.Kind() == reflect.String:
+		return PropertyValue{Type: "rich_text", RichText: []RichText{NewText(f.String())}}, nil
+	case f.Kind() == reflect.Bool:
+		return PropertyValue{Type: "checkbox", Checkbox: f.Bool()}, nil
+	case isInt(f.Kind()):
+		number := float64(f.Int())
+		return PropertyValue{Type: "number", Number: &number}, nil
+	case isFloat(f.Kind()):
+		number := f.Float()
+		return PropertyValue{Type: "number", Number: &number}, nil
+	case f.Kind() == reflect.Slice && f.Type().Elem().Kind() == reflect.String:
+		values := make([]MultiSelectPropertyValue, 0, f.Len())
+		for i := 0; i < f.Len(); i++ {
+			values = append(values, MultiSelectPropertyValue{Name: f.Index(i).String()})
+		}
+		return PropertyValue{Type: "multi_select", MultiSelect: values}, nil
+	default:
+		return PropertyValue{}, fmt.Errorf("notion: can't marshal property %q of type %s", tag.name, f.Type())
+	}
+}
+
+func bindField(f reflect.Value, value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	v := reflect.ValueOf(value)
+	switch {
+	case v.Type().AssignableTo(f.Type()):
+		f.Set(v)
+	case isFloat(v.Kind()) && isFloat(f.Kind()):
+		f.SetFloat(v.Float())
+	case isFloat(v.Kind()) && isInt(f.Kind()):
+		f.SetInt(int64(v.Float()))
+	default:
+		return fmt.Errorf("can't assign %s to %s", v.Type(), f.Type())
+	}
+	return nil
+}
+
+func isInt(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	default:
+		return false
+	}
+}
+
+func isFloat(k reflect.Kind) bool {
+	return k == reflect.Float32 || k == reflect.Float64
+}
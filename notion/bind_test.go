@@ -0,0 +1,64 @@
+package notion
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type bindTestTask struct {
+	Title    string   `notion:"Task,title"`
+	Coffee   bool     `notion:"Needs ☕️?"`
+	Tags     []string `notion:"Tag"`
+	Priority int      `notion:"Priority"`
+	Ignored  string
+}
+
+func TestBindPage(t *testing.T) {
+	var got bindTestTask
+
+	if err := BindPage(flattenTestPage, &got); err != nil {
+		t.Fatalf("BindPage() error = %v, wantErr <nil>", err)
+	}
+
+	want := bindTestTask{
+		Title:  "Write more integrations tests",
+		Coffee: true,
+		Tags:   []string{"go", "software-engineering"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("BindPage() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestMarshalPage(t *testing.T) {
+	task := bindTestTask{
+		Title:    "Buy milk",
+		Coffee:   true,
+		Tags:     []string{"errand"},
+		Priority: 2,
+		Ignored:  "not a property",
+	}
+
+	got, err := MarshalPage(task)
+	if err != nil {
+		t.Fatalf("MarshalPage() error = %v, wantErr <nil>", err)
+	}
+
+	priority := 2.0
+	want := map[string]PropertyValue{
+		"Task": {
+			Type:  "title",
+			Title: []RichText{{Type: "text", Text: &Text{Content: "Buy milk"}}},
+		},
+		"Needs ☕️?": {Type: "checkbox", Checkbox: true},
+		"Tag": {
+			Type:        "multi_select",
+			MultiSelect: []MultiSelectPropertyValue{{Name: "errand"}},
+		},
+		"Priority": {Type: "number", Number: &priority},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("MarshalPage() mismatch (-want +got):\n%s", diff)
+	}
+}
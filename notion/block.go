@@ -0,0 +1,247 @@
+package notion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Block represents a block of content on a page
+//
+// Only one of the type specific fields, the one matching Type, is set.
+//
+// See https://developers.notion.com/reference/block
+type Block struct {
+	Object           string     `json:"object,omitempty"`
+	ID               string     `json:"id,omitempty"`
+	Type             string     `json:"type,omitempty"`
+	CreatedTime      string     `json:"created_time,omitempty"`
+	LastEditedTime   string     `json:"last_edited_time,omitempty"`
+	HasChildren      bool       `json:"has_children,omitempty"`
+	Archived         bool       `json:"archived,omitempty"`
+	Paragraph        *TextBlock `json:"paragraph,omitempty"`
+	Heading1         *TextBlock `json:"heading_1,omitempty"`
+	Heading2         *TextBlock `json:"heading_2,omitempty"`
+	Heading3         *TextBlock `json:"heading_3,omitempty"`
+	BulletedListItem *TextBlock `json:"bulleted_list_item,omitempty"`
+	NumberedListItem *TextBlock `json:"numbered_list_item,omitempty"`
+	ToDo             *ToDoBlock `json:"to_do,omitempty"`
+	Toggle           *TextBlock `json:"toggle,omitempty"`
+	// Children are the nested blocks sent along when the block is appended, the API accepts up to two levels of nesting
+	Children []Block `json:"-"`
+}
+
+// MarshalJSON encodes the block, the children are nested in the type specific object, e.g. {"toggle":{"children":[]}}
+func (b Block) MarshalJSON() ([]byte, error) {
+	type block Block
+	data, err := json.Marshal(block(b))
+	if err != nil || len(b.Children) == 0 {
+		return data, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	content, ok := fields[b.Type]
+	if !ok {
+		return nil, fmt.Errorf("block of type %q with children has no %q content", b.Type, b.Type)
+	}
+	var contentFields map[string]json.RawMessage
+	if err := json.Unmarshal(content, &contentFields); err != nil {
+		return nil, err
+	}
+	if contentFields["children"], err = json.Marshal(b.Children); err != nil {
+		return nil, err
+	}
+	if fields[b.Type], err = json.Marshal(contentFields); err != nil {
+		return nil, err
+	}
+	return json.Marshal(fields)
+}
+
+// CreatedAt parses the CreatedTime of the block
+func (b *Block) CreatedAt() (time.Time, error) {
+	return parseTimestamp(b.CreatedTime)
+}
+
+// LastEditedAt parses the LastEditedTime of the block
+func (b *Block) LastEditedAt() (time.Time, error) {
+	return parseTimestamp(b.LastEditedTime)
+}
+
+// content returns a copy of the block with only the type specific fields set, e.g. to update the block
+func (b *Block) content() Block {
+	return Block{
+		Paragraph:        b.Paragraph,
+		Heading1:         b.Heading1,
+		Heading2:         b.Heading2,
+		Heading3:         b.Heading3,
+		BulletedListItem: b.BulletedListItem,
+		NumberedListItem: b.NumberedListItem,
+		ToDo:             b.ToDo,
+		Toggle:           b.Toggle,
+	}
+}
+
+// TextBlock holds the content of the text-like blocks, e.g. paragraphs, headings and list items
+//
+// See https://developers.notion.com/reference/block#paragraph-blocks
+type TextBlock struct {
+	Text []RichText `json:"text,omitempty"`
+}
+
+// ToDoBlock holds the content of a to do block
+//
+// See https://developers.notion.com/reference/block#to-do-blocks
+type ToDoBlock struct {
+	Text    []RichText `json:"text,omitempty"`
+	Checked bool       `json:"checked,omitempty"`
+}
+
+// BlockList is a response to the retrieve block children endpoint
+//
+// See https://developers.notion.com/reference/get-block-children
+// See https://developers.notion.com/reference/pagination
+type BlockList struct {
+	Object     string  `json:"object,omitempty"`
+	Results    []Block `json:"results,omitempty"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+	HasMore    bool    `json:"has_more,omitempty"`
+}
+
+// UnmarshalJSON decodes the list, null results are decoded as an empty slice
+func (l *BlockList) UnmarshalJSON(data []byte) error {
+	type list BlockList
+	if err := json.Unmarshal(data, (*list)(l)); err != nil {
+		return err
+	}
+	if l.Results == nil {
+		l.Results = []Block{}
+	}
+	return nil
+}
+
+// More reports whether there are more results to fetch
+func (l *BlockList) More() bool {
+	return l.HasMore
+}
+
+// Cursor returns the cursor of the next page of results, see Pagination.StartCursor
+func (l *BlockList) Cursor() string {
+	return l.NextCursor
+}
+
+// RetrieveBlock retrieves a Block object using the ID specified
+//
+// See https://developers.notion.com/reference/retrieve-a-block
+func (s *Service) RetrieveBlock(ctx context.Context, blockID string) (*Block, error) {
+	blockID, err := normalizeID(blockID)
+	if err != nil {
+		return nil, err
+	}
+	block := &Block{}
+	apiErr := &Error{}
+	if err := s.client.Do(ctx, http.MethodGet, fmt.Sprintf("/blocks/%s", blockID), nil, nil, block, apiErr); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+// RetrieveBlockChildren returns the blocks nested directly under the given block or page
+//
+// See https://developers.notion.com/reference/get-block-children
+func (s *Service) RetrieveBlockChildren(ctx context.Context, blockID string, page Pagination) (*BlockList, error) {
+	blockID, err := normalizeID(blockID)
+	if err != nil {
+		return nil, err
+	}
+	if err := page.validate(); err != nil {
+		return nil, err
+	}
+	blocks := &BlockList{}
+	apiErr := &Error{}
+	if err := s.client.Do(
+		ctx,
+		http.MethodGet,
+		fmt.Sprintf("/blocks/%s/children", blockID),
+		page.query(),
+		nil,
+		blocks,
+		apiErr,
+	); err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+// AppendBlockChildren adds the children blocks at the end of the given block or page
+//
+// See https://developers.notion.com/reference/patch-block-children
+func (s *Service) AppendBlockChildren(ctx context.Context, blockID string, children []Block) (*BlockList, error) {
+	blockID, err := normalizeID(blockID)
+	if err != nil {
+		return nil, err
+	}
+	type Payload struct {
+		Children []Block `json:"children"`
+	}
+	blocks := &BlockList{}
+	apiErr := &Error{}
+	if err := s.client.Do(
+		ctx,
+		http.MethodPatch,
+		fmt.Sprintf("/blocks/%s/children", blockID),
+		nil,
+		&Payload{Children: children},
+		blocks,
+		apiErr,
+	); err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+// DeleteBlock archives the given block, it's moved to the trash and can be restored in the UI
+//
+// See https://developers.notion.com/reference/delete-a-block
+func (s *Service) DeleteBlock(ctx context.Context, blockID string) (*Block, error) {
+	blockID, err := normalizeID(blockID)
+	if err != nil {
+		return nil, err
+	}
+	block := &Block{}
+	apiErr := &Error{}
+	if err := s.client.Do(ctx, http.MethodDelete, fmt.Sprintf("/blocks/%s", blockID), nil, nil, block, apiErr); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+// UpdateBlock replaces the content of the given block, only the type specific field of the block, e.g. Paragraph, is
+// sent
+//
+// See https://developers.notion.com/reference/update-a-block
+func (s *Service) UpdateBlock(ctx context.Context, blockID string, block Block) (*Block, error) {
+	blockID, err := normalizeID(blockID)
+	if err != nil {
+		return nil, err
+	}
+	payload := block.content()
+	updated := &Block{}
+	apiErr := &Error{}
+	if err := s.client.Do(
+		ctx,
+		http.MethodPatch,
+		fmt.Sprintf("/blocks/%s", blockID),
+		nil,
+		&payload,
+		updated,
+		apiErr,
+	); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
@@ -0,0 +1,430 @@
+package notion
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestService_RetrieveBlock(t *testing.T) {
+	httpClient, capturedRequest := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 200,
+			Body: ioutil.NopCloser(bytes.NewBufferString(`{
+			  "object": "block",
+			  "id": "9bc30ad4-9373-46a5-84ab-0a7845ee52e6",
+			  "created_time": "2021-05-20T09:18:00.000Z",
+			  "last_edited_time": "2021-05-20T09:19:00.000Z",
+			  "has_children": false,
+			  "type": "heading_2",
+			  "heading_2": {
+				"text": [
+				  {
+					"type": "text",
+					"text": {"content": "Lacinato kale", "link": null},
+					"plain_text": "Lacinato kale",
+					"href": null
+				  }
+				]
+			  }
+			}`)),
+		}, nil
+	})
+	service := New("token", WithHTTPClient(httpClient))
+
+	gotBlock, gotErr := service.RetrieveBlock(context.Background(), "9bc30ad4-9373-46a5-84ab-0a7845ee52e6")
+	if gotErr != nil {
+		t.Fatalf("RetrieveBlock() error = %v, wantErr <nil>", gotErr)
+	}
+
+	wantPath := "/v1/blocks/9bc30ad4-9373-46a5-84ab-0a7845ee52e6"
+	if gotPath := capturedRequest.URL.Path; gotPath != wantPath {
+		t.Errorf("path = %v, want %v", gotPath, wantPath)
+	}
+	wantBlock := &Block{
+		Object:         "block",
+		ID:             "9bc30ad4-9373-46a5-84ab-0a7845ee52e6",
+		CreatedTime:    "2021-05-20T09:18:00.000Z",
+		LastEditedTime: "2021-05-20T09:19:00.000Z",
+		Type:           "heading_2",
+		Heading2: &TextBlock{
+			Text: []RichText{
+				{Type: "text", Text: &Text{Content: "Lacinato kale"}, PlainText: "Lacinato kale"},
+			},
+		},
+	}
+	if diff := cmp.Diff(wantBlock, gotBlock); diff != "" {
+		t.Errorf("RetrieveBlock() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestService_RetrieveBlockChildren(t *testing.T) {
+	httpClient, capturedRequest := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 200,
+			Body: ioutil.NopCloser(bytes.NewBufferString(`{
+			  "object": "list",
+			  "results": [
+				{
+				  "object": "block",
+				  "id": "9bc30ad4-9373-46a5-84ab-0a7845ee52e6",
+				  "has_children": false,
+				  "type": "paragraph",
+				  "paragraph": {
+					"text": [
+					  {
+						"type": "text",
+						"text": {"content": "Lacinato kale is a variety of kale.", "link": null},
+						"plain_text": "Lacinato kale is a variety of kale.",
+						"href": null
+					  }
+					]
+				  }
+				},
+				{
+				  "object": "block",
+				  "id": "7face6fd-3ef4-4b38-b1dc-c5044988eec0",
+				  "has_children": false,
+				  "type": "to_do",
+				  "to_do": {
+					"text": [
+					  {
+						"type": "text",
+						"text": {"content": "Buy seeds", "link": null},
+						"plain_text": "Buy seeds",
+						"href": null
+					  }
+					],
+					"checked": true
+				  }
+				}
+			  ],
+			  "next_cursor": null,
+			  "has_more": false
+			}`)),
+		}, nil
+	})
+	service := New("token", WithHTTPClient(httpClient))
+
+	gotBlocks, gotErr := service.RetrieveBlockChildren(
+		context.Background(),
+		"b55c9c91-384d-452b-81db-d1ef79372b75",
+		Pagination{PageSize: 50},
+	)
+	if gotErr != nil {
+		t.Fatalf("RetrieveBlockChildren() error = %v, wantErr <nil>", gotErr)
+	}
+
+	wantURL := "https://api.notion.com/v1/blocks/b55c9c91-384d-452b-81db-d1ef79372b75/children?page_size=50"
+	if gotURL := capturedRequest.URL.String(); gotURL != wantURL {
+		t.Errorf("url = %v, want %v", gotURL, wantURL)
+	}
+	wantBlocks := &BlockList{
+		Object: "list",
+		Results: []Block{
+			{
+				Object: "block",
+				ID:     "9bc30ad4-9373-46a5-84ab-0a7845ee52e6",
+				Type:   "paragraph",
+				Paragraph: &TextBlock{
+					Text: []RichText{
+						{
+							Type:      "text",
+							Text:      &Text{Content: "Lacinato kale is a variety of kale."},
+							PlainText: "Lacinato kale is a variety of kale.",
+						},
+					},
+				},
+			},
+			{
+				Object: "block",
+				ID:     "7face6fd-3ef4-4b38-b1dc-c5044988eec0",
+				Type:   "to_do",
+				ToDo: &ToDoBlock{
+					Text: []RichText{
+						{Type: "text", Text: &Text{Content: "Buy seeds"}, PlainText: "Buy seeds"},
+					},
+					Checked: true,
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(wantBlocks, gotBlocks); diff != "" {
+		t.Errorf("RetrieveBlockChildren() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestService_AppendBlockChildren(t *testing.T) {
+	httpClient, capturedRequest := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 200,
+			Body: ioutil.NopCloser(bytes.NewBufferString(`{
+			  "object": "list",
+			  "results": [
+				{
+				  "object": "block",
+				  "id": "9bc30ad4-9373-46a5-84ab-0a7845ee52e6",
+				  "type": "paragraph",
+				  "paragraph": {
+					"text": [{"type": "text", "text": {"content": "Hello"}, "plain_text": "Hello"}]
+				  }
+				}
+			  ],
+			  "next_cursor": null,
+			  "has_more": false
+			}`)),
+		}, nil
+	})
+	service := New("token", WithHTTPClient(httpClient))
+
+	gotBlocks, gotErr := service.AppendBlockChildren(
+		context.Background(),
+		"b55c9c91-384d-452b-81db-d1ef79372b75",
+		[]Block{
+			{
+				Type:      "paragraph",
+				Paragraph: &TextBlock{Text: []RichText{{Type: "text", Text: &Text{Content: "Hello"}}}},
+			},
+		},
+	)
+	if gotErr != nil {
+		t.Fatalf("AppendBlockChildren() error = %v, wantErr <nil>", gotErr)
+	}
+
+	if capturedRequest.Method != http.MethodPatch {
+		t.Errorf("method = %v, want %v", capturedRequest.Method, http.MethodPatch)
+	}
+	wantPath := "/v1/blocks/b55c9c91-384d-452b-81db-d1ef79372b75/children"
+	if gotPath := capturedRequest.URL.Path; gotPath != wantPath {
+		t.Errorf("path = %v, want %v", gotPath, wantPath)
+	}
+	payload, _ := ioutil.ReadAll(capturedRequest.Body)
+	wantPayload := `{"children":[{"type":"paragraph","paragraph":{"text":[{"type":"text","text":{"content":"Hello"}}]}}]}`
+	if string(payload) != wantPayload {
+		t.Errorf("payload = %s, want %s", payload, wantPayload)
+	}
+	if len(gotBlocks.Results) != 1 || gotBlocks.Results[0].ID != "9bc30ad4-9373-46a5-84ab-0a7845ee52e6" {
+		t.Errorf("AppendBlockChildren() = %+v, want the appended block", gotBlocks)
+	}
+}
+
+func TestService_DeleteBlock(t *testing.T) {
+	tests := []struct {
+		name           string
+		respStatusCode int
+		respBody       string
+		wantBlock      *Block
+		wantErrMsg     string
+	}{
+		{
+			name:           "should return the archived block",
+			respStatusCode: 200,
+			respBody: `{
+			  "object": "block",
+			  "id": "9bc30ad4-9373-46a5-84ab-0a7845ee52e6",
+			  "type": "paragraph",
+			  "archived": true,
+			  "paragraph": {"text": []}
+			}`,
+			wantBlock: &Block{
+				Object:    "block",
+				ID:        "9bc30ad4-9373-46a5-84ab-0a7845ee52e6",
+				Type:      "paragraph",
+				Archived:  true,
+				Paragraph: &TextBlock{Text: []RichText{}},
+			},
+		},
+		{
+			name:           "should parse an error",
+			respStatusCode: 404,
+			respBody: `{
+			  "object": "error",
+			  "status": 404,
+			  "code": "object_not_found",
+			  "message": "Could not find block with ID: 9bc30ad4-9373-46a5-84ab-0a7845ee52e6."
+			}`,
+			wantErrMsg: "application error: &{object_not_found Could not find block with ID: 9bc30ad4-9373-46a5-84ab-0a7845ee52e6.}",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient, capturedRequest := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: tt.respStatusCode,
+					Body:       ioutil.NopCloser(bytes.NewBufferString(tt.respBody)),
+				}, nil
+			})
+			service := New("token", WithHTTPClient(httpClient))
+
+			gotBlock, gotErr := service.DeleteBlock(context.Background(), "9bc30ad4937346a584ab0a7845ee52e6")
+
+			if capturedRequest.Method != http.MethodDelete {
+				t.Errorf("method = %v, want %v", capturedRequest.Method, http.MethodDelete)
+			}
+			wantPath := "/v1/blocks/9bc30ad4-9373-46a5-84ab-0a7845ee52e6"
+			if gotPath := capturedRequest.URL.Path; gotPath != wantPath {
+				t.Errorf("path = %v, want %v", gotPath, wantPath)
+			}
+			if capturedRequest.Body != nil || capturedRequest.Header.Get("Content-Type") != "" {
+				t.Errorf("request has a body, want none")
+			}
+			if tt.wantErrMsg != "" {
+				if gotErr == nil {
+					gotErr = fmt.Errorf("no error")
+				}
+				if !strings.Contains(gotErr.Error(), tt.wantErrMsg) {
+					t.Errorf("DeleteBlock() error = %v, wantErr %v", gotErr, tt.wantErrMsg)
+				}
+			} else if gotErr != nil {
+				t.Errorf("DeleteBlock() error = %v, wantErr <nil>", gotErr)
+			}
+			if diff := cmp.Diff(tt.wantBlock, gotBlock); diff != "" {
+				t.Errorf("DeleteBlock() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestService_UpdateBlock(t *testing.T) {
+	httpClient, capturedRequest := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 200,
+			Body: ioutil.NopCloser(bytes.NewBufferString(`{
+			  "object": "block",
+			  "id": "9bc30ad4-9373-46a5-84ab-0a7845ee52e6",
+			  "type": "paragraph",
+			  "paragraph": {
+				"text": [{"type": "text", "text": {"content": "Hello, world"}, "plain_text": "Hello, world"}]
+			  }
+			}`)),
+		}, nil
+	})
+	service := New("token", WithHTTPClient(httpClient))
+
+	gotBlock, gotErr := service.UpdateBlock(context.Background(), "9bc30ad4-9373-46a5-84ab-0a7845ee52e6", Block{
+		Object:      "block",
+		ID:          "9bc30ad4-9373-46a5-84ab-0a7845ee52e6",
+		Type:        "paragraph",
+		HasChildren: true,
+		Paragraph:   &TextBlock{Text: []RichText{NewText("Hello, world")}},
+	})
+	if gotErr != nil {
+		t.Fatalf("UpdateBlock() error = %v, wantErr <nil>", gotErr)
+	}
+
+	if capturedRequest.Method != http.MethodPatch {
+		t.Errorf("method = %v, want %v", capturedRequest.Method, http.MethodPatch)
+	}
+	wantPath := "/v1/blocks/9bc30ad4-9373-46a5-84ab-0a7845ee52e6"
+	if gotPath := capturedRequest.URL.Path; gotPath != wantPath {
+		t.Errorf("path = %v, want %v", gotPath, wantPath)
+	}
+	payload, _ := ioutil.ReadAll(capturedRequest.Body)
+	wantPayload := `{"paragraph":{"text":[{"type":"text","text":{"content":"Hello, world"}}]}}`
+	if string(payload) != wantPayload {
+		t.Errorf("payload = %s, want %s", payload, wantPayload)
+	}
+	if got := PlainText(gotBlock.Paragraph.Text); got != "Hello, world" {
+		t.Errorf("UpdateBlock() text = %q, want %q", got, "Hello, world")
+	}
+}
+
+func TestBlock_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name       string
+		block      Block
+		want       string
+		wantErrMsg string
+	}{
+		{
+			name: "should omit empty children",
+			block: Block{
+				Type:      "paragraph",
+				Paragraph: &TextBlock{Text: []RichText{NewText("Hello")}},
+			},
+			want: `{"type":"paragraph","paragraph":{"text":[{"type":"text","text":{"content":"Hello"}}]}}`,
+		},
+		{
+			name: "should nest the children in the type specific object",
+			block: Block{
+				Type:             "bulleted_list_item",
+				BulletedListItem: &TextBlock{Text: []RichText{NewText("Item")}},
+				Children: []Block{
+					{Type: "paragraph", Paragraph: &TextBlock{Text: []RichText{NewText("Details")}}},
+				},
+			},
+			want: `{"bulleted_list_item":{"children":[{"type":"paragraph","paragraph":{"text":[{"type":"text","text":{"content":"Details"}}]}}],"text":[{"type":"text","text":{"content":"Item"}}]},"type":"bulleted_list_item"}`,
+		},
+		{
+			name: "should reject children without the type specific content",
+			block: Block{
+				Type:     "toggle",
+				Children: []Block{{Type: "paragraph", Paragraph: &TextBlock{}}},
+			},
+			wantErrMsg: `block of type "toggle" with children has no "toggle" content`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotErr := json.Marshal(tt.block)
+
+			if tt.wantErrMsg != "" {
+				if gotErr == nil {
+					gotErr = fmt.Errorf("no error")
+				}
+				if !strings.Contains(gotErr.Error(), tt.wantErrMsg) {
+					t.Errorf("MarshalJSON() error = %v, wantErr %v", gotErr, tt.wantErrMsg)
+				}
+				return
+			}
+			if gotErr != nil {
+				t.Fatalf("MarshalJSON() error = %v, wantErr <nil>", gotErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("MarshalJSON() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_AppendBlockChildren_Nested(t *testing.T) {
+	httpClient, capturedRequest := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"object":"list","results":[],"next_cursor":null,"has_more":false}`)),
+		}, nil
+	})
+	service := New("token", WithHTTPClient(httpClient))
+
+	_, gotErr := service.AppendBlockChildren(
+		context.Background(),
+		"b55c9c91-384d-452b-81db-d1ef79372b75",
+		[]Block{
+			{
+				Type:             "bulleted_list_item",
+				BulletedListItem: &TextBlock{Text: []RichText{NewText("Item")}},
+				Children: []Block{
+					{Type: "paragraph", Paragraph: &TextBlock{Text: []RichText{NewText("Details")}}},
+				},
+			},
+		},
+	)
+	if gotErr != nil {
+		t.Fatalf("AppendBlockChildren() error = %v, wantErr <nil>", gotErr)
+	}
+
+	payload, _ := ioutil.ReadAll(capturedRequest.Body)
+	wantPayload := `{"children":[{"bulleted_list_item":{"children":[{"type":"paragraph","paragraph":{"text":[{"type":"text","text":{"content":"Details"}}]}}],"text":[{"type":"text","text":{"content":"Item"}}]},"type":"bulleted_list_item"}]}`
+	if string(payload) != wantPayload {
+		t.Errorf("payload = %s, want %s", payload, wantPayload)
+	}
+}
@@ -1,19 +1,53 @@
 package notion
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"strconv"
+	"strings"
+	"time"
+)
+
+// Color is the color of a text or of a select option, the options can't use the background colors
+//
+// See https://developers.notion.com/reference/rich-text#annotations
+type Color string
+
+// The colors supported by Notion
+const (
+	ColorDefault Color = "default"
+	ColorGray    Color = "gray"
+	ColorBrown   Color = "brown"
+	ColorOrange  Color = "orange"
+	ColorYellow  Color = "yellow"
+	ColorGreen   Color = "green"
+	ColorBlue    Color = "blue"
+	ColorPurple  Color = "purple"
+	ColorPink    Color = "pink"
+	ColorRed     Color = "red"
+
+	ColorGrayBackground   Color = "gray_background"
+	ColorBrownBackground  Color = "brown_background"
+	ColorOrangeBackground Color = "orange_background"
+	ColorYellowBackground Color = "yellow_background"
+	ColorGreenBackground  Color = "green_background"
+	ColorBlueBackground   Color = "blue_background"
+	ColorPurpleBackground Color = "purple_background"
+	ColorPinkBackground   Color = "pink_background"
+	ColorRedBackground    Color = "red_background"
 )
 
 // Annotations contains style information which applies to the whole rich text object.
 //
 // See https://developers.notion.com/reference/rich-text#annotations
 type Annotations struct {
-	Bold          bool   `json:"bold,omitempty"`
-	Italic        bool   `json:"italic,omitempty"`
-	Strikethrough bool   `json:"strikethrough,omitempty"`
-	Underline     bool   `json:"underline,omitempty"`
-	Code          bool   `json:"code,omitempty"`
-	Color         string `json:"color,omitempty"`
+	Bold          bool  `json:"bold,omitempty"`
+	Italic        bool  `json:"italic,omitempty"`
+	Strikethrough bool  `json:"strikethrough,omitempty"`
+	Underline     bool  `json:"underline,omitempty"`
+	Code          bool  `json:"code,omitempty"`
+	Color         Color `json:"color,omitempty"`
 }
 
 // RichText objects combine a text content with syle information
@@ -26,17 +60,159 @@ type RichText struct {
 	PlainText   string       `json:"plain_text,omitempty"`
 	Href        string       `json:"href,omitempty"`
 	Content     string       `json:"content,omitempty"`
-	// TODO: links
-	// TODO: mentions
+	Mention     *Mention     `json:"mention,omitempty"`
 	// TODO: equations
 }
 
+// UnmarshalJSON decodes the rich text, Href falls back to the URL of the text link if the API didn't set it
+func (rt *RichText) UnmarshalJSON(data []byte) error {
+	type richText RichText
+	if err := json.Unmarshal(data, (*richText)(rt)); err != nil {
+		return err
+	}
+	if rt.Href == "" && rt.Text != nil && rt.Text.Link != nil {
+		rt.Href = rt.Text.Link.URL
+	}
+	return nil
+}
+
+// WithBold returns a copy of the rich text with the bold annotation set
+func (rt RichText) WithBold() RichText {
+	return rt.annotate(func(a *Annotations) { a.Bold = true })
+}
+
+// WithItalic returns a copy of the rich text with the italic annotation set
+func (rt RichText) WithItalic() RichText {
+	return rt.annotate(func(a *Annotations) { a.Italic = true })
+}
+
+// WithStrikethrough returns a copy of the rich text with the strikethrough annotation set
+func (rt RichText) WithStrikethrough() RichText {
+	return rt.annotate(func(a *Annotations) { a.Strikethrough = true })
+}
+
+// WithUnderline returns a copy of the rich text with the underline annotation set
+func (rt RichText) WithUnderline() RichText {
+	return rt.annotate(func(a *Annotations) { a.Underline = true })
+}
+
+// WithCode returns a copy of the rich text with the code annotation set
+func (rt RichText) WithCode() RichText {
+	return rt.annotate(func(a *Annotations) { a.Code = true })
+}
+
+// WithColor returns a copy of the rich text with the color annotation set
+func (rt RichText) WithColor(color Color) RichText {
+	return rt.annotate(func(a *Annotations) { a.Color = color })
+}
+
+// annotate applies f to a copy of the annotations, so the annotations already set are preserved and the original
+// rich text is left untouched
+func (rt RichText) annotate(f func(a *Annotations)) RichText {
+	var a Annotations
+	if rt.Annotations != nil {
+		a = *rt.Annotations
+	}
+	f(&a)
+	rt.Annotations = &a
+	return rt
+}
+
+// NewText builds a plain text rich text object with the given content, e.g. for a title in CreatePage
+func NewText(content string) RichText {
+	return RichText{Type: "text", Text: &Text{Content: content}}
+}
+
+// NewLinkedText builds a text rich text object with the given content linking to url
+func NewLinkedText(content, url string) RichText {
+	rt := NewText(content)
+	rt.Text.Link = &TextLink{URL: url}
+	return rt
+}
+
+// NewAnnotatedText builds a text rich text object with the given content and style
+func NewAnnotatedText(content string, a Annotations) RichText {
+	rt := NewText(content)
+	rt.Annotations = &a
+	return rt
+}
+
+// TitleValue builds a title property value with plain text content
+func TitleValue(content string) PropertyValue {
+	return PropertyValue{Type: "title", Title: []RichText{NewText(content)}}
+}
+
+// DateValue builds a date property value, a range if end is set
+//
+// The times are formatted as RFC 3339 in their own location, or as a bare date, e.g. "2021-05-12", when they have no
+// time component.
+func DateValue(start time.Time, end *time.Time) PropertyValue {
+	date := &DatePropertyValue{Start: formatDate(start)}
+	if end != nil {
+		date.End = formatDate(*end)
+	}
+	return PropertyValue{Type: "date", Date: date}
+}
+
+func formatDate(t time.Time) string {
+	if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0 {
+		return t.Format("2006-01-02")
+	}
+	return t.Format(time.RFC3339)
+}
+
+// PlainText concatenates the plain text of the rich text objects, dropping the annotations and links
+func PlainText(rt []RichText) string {
+	var sb strings.Builder
+	for _, t := range rt {
+		sb.WriteString(t.PlainText)
+	}
+	return sb.String()
+}
+
+// PlainText returns the text of a title or rich_text property value, empty for the other property types
+func (pv PropertyValue) PlainText() string {
+	switch pv.Type {
+	case "title":
+		return PlainText(pv.Title)
+	case "rich_text":
+		return PlainText(pv.RichText)
+	default:
+		return ""
+	}
+}
+
 // Text object
 //
 // See https://developers.notion.com/reference/rich-text#text-objects
 type Text struct {
-	Content string `json:"content,omitempty"`
-	// TODO: link
+	Content string    `json:"content,omitempty"`
+	Link    *TextLink `json:"link,omitempty"`
+}
+
+// Mention object references a user, a page, a database or a date inline in the rich text
+//
+// Type tells which of the fields is set. The PlainText of the rich text carries the text displayed for the mention.
+//
+// See https://developers.notion.com/reference/rich-text#mention-objects
+type Mention struct {
+	Type     string             `json:"type,omitempty"`
+	User     *User              `json:"user,omitempty"`
+	Page     *ObjectReference   `json:"page,omitempty"`
+	Database *ObjectReference   `json:"database,omitempty"`
+	Date     *DatePropertyValue `json:"date,omitempty"`
+}
+
+// ObjectReference points to a page or a database by its ID
+type ObjectReference struct {
+	ID string `json:"id,omitempty"`
+}
+
+// TextLink is the hyperlink of a text object
+//
+// See https://developers.notion.com/reference/rich-text#text-objects
+type TextLink struct {
+	URL string `json:"url,omitempty"`
 }
 
 // Property represents any type of the property object
@@ -46,11 +222,22 @@ type Property struct {
 	ID             string                  `json:"id,omitempty"`
 	Type           string                  `json:"type,omitempty"`
 	Title          *TitleProperty          `json:"title,omitempty"`
+	Number         *NumberProperty         `json:"number,omitempty"`
 	Select         *SelectProperty         `json:"select,omitempty"`
 	MultiSelect    *MultiSelectProperty    `json:"multi_select,omitempty"`
 	Checkbox       *CheckboxProperty       `json:"checkbox,omitempty"`
 	CreatedTime    *CreatedTimeProperty    `json:"created_time,omitempty"`
 	LastEditedTime *LastEditedTimeProperty `json:"last_edited_time,omitempty"`
+	Status         *StatusProperty         `json:"status,omitempty"`
+	URL            *URLProperty            `json:"url,omitempty"`
+	Email          *EmailProperty          `json:"email,omitempty"`
+	PhoneNumber    *PhoneNumberProperty    `json:"phone_number,omitempty"`
+	Date           *DateProperty           `json:"date,omitempty"`
+	People         *PeopleProperty         `json:"people,omitempty"`
+	Files          *FilesProperty          `json:"files,omitempty"`
+	Relation       *RelationProperty       `json:"relation,omitempty"`
+	Rollup         *RollupProperty         `json:"rollup,omitempty"`
+	Formula        *FormulaProperty        `json:"formula,omitempty"`
 }
 
 // TitleProperty represents the title property
@@ -58,6 +245,13 @@ type Property struct {
 // See https://developers.notion.com/reference/database#title-configuration
 type TitleProperty struct{}
 
+// NumberProperty represents the number property, Format tells how the numbers are displayed, e.g. "dollar" or "percent"
+//
+// See https://developers.notion.com/reference/database#number-configuration
+type NumberProperty struct {
+	Format string `json:"format,omitempty"`
+}
+
 // SelectProperty represents the select property
 //
 // See https://developers.notion.com/reference/database#select-configuration
@@ -71,7 +265,7 @@ type SelectProperty struct {
 type SelectOption struct {
 	ID    string `json:"id,omitempty"`
 	Name  string `json:"name,omitempty"`
-	Color string `json:"color,omitempty"`
+	Color Color  `json:"color,omitempty"`
 }
 
 // MultiSelectProperty represents the select property
@@ -87,7 +281,34 @@ type MultiSelectProperty struct {
 type MultiSelectOption struct {
 	ID    string `json:"id,omitempty"`
 	Name  string `json:"name,omitempty"`
-	Color string `json:"color,omitempty"`
+	Color Color  `json:"color,omitempty"`
+}
+
+// StatusProperty represents the status property, its options are split into groups, e.g. "To-do" or "Complete"
+//
+// See https://developers.notion.com/reference/property-object#status
+type StatusProperty struct {
+	Options []StatusOption `json:"options,omitempty"`
+	Groups  []StatusGroup  `json:"groups,omitempty"`
+}
+
+// StatusOption represents the options to StatusProperty
+//
+// See https://developers.notion.com/reference/property-object#status
+type StatusOption struct {
+	ID    string `json:"id,omitempty"`
+	Name  string `json:"name,omitempty"`
+	Color Color  `json:"color,omitempty"`
+}
+
+// StatusGroup represents a group of the StatusProperty options
+//
+// See https://developers.notion.com/reference/property-object#status
+type StatusGroup struct {
+	ID        string   `json:"id,omitempty"`
+	Name      string   `json:"name,omitempty"`
+	Color     Color    `json:"color,omitempty"`
+	OptionIDs []string `json:"option_ids,omitempty"`
 }
 
 // CheckboxProperty represents the checkbox property
@@ -105,8 +326,82 @@ type CreatedTimeProperty struct{}
 // See https://developers.notion.com/reference/database#last-edited-time-configuration
 type LastEditedTimeProperty struct{}
 
+// URLProperty represents the url property
+//
+// See https://developers.notion.com/reference/database#url-configuration
+type URLProperty struct{}
+
+// EmailProperty represents the email property
+//
+// See https://developers.notion.com/reference/database#email-configuration
+type EmailProperty struct{}
+
+// PhoneNumberProperty represents the phone number property
+//
+// See https://developers.notion.com/reference/database#phone-number-configuration
+type PhoneNumberProperty struct{}
+
+// DateProperty represents the date property
+//
+// See https://developers.notion.com/reference/database#date-configuration
+type DateProperty struct{}
+
+// PeopleProperty represents the people property
+//
+// See https://developers.notion.com/reference/database#people-configuration
+type PeopleProperty struct{}
+
+// FilesProperty represents the files property
+//
+// See https://developers.notion.com/reference/database#files-configuration
+type FilesProperty struct{}
+
+// RelationProperty represents the relation property, it links the pages to the pages of the other database
+//
+// See https://developers.notion.com/reference/database#relation-configuration
+type RelationProperty struct {
+	DatabaseID         string `json:"database_id,omitempty"`
+	SyncedPropertyName string `json:"synced_property_name,omitempty"`
+	SyncedPropertyID   string `json:"synced_property_id,omitempty"`
+}
+
+// RollupProperty represents the rollup property, it aggregates a property of the pages linked with a relation
+//
+// See https://developers.notion.com/reference/database#rollup-configuration
+type RollupProperty struct {
+	RelationPropertyName string `json:"relation_property_name,omitempty"`
+	RelationPropertyID   string `json:"relation_property_id,omitempty"`
+	RollupPropertyName   string `json:"rollup_property_name,omitempty"`
+	RollupPropertyID     string `json:"rollup_property_id,omitempty"`
+	Function             string `json:"function,omitempty"`
+}
+
+// FormulaProperty represents the formula property
+//
+// See https://developers.notion.com/reference/database#formula-configuration
+type FormulaProperty struct {
+	Expression string `json:"expression,omitempty"`
+}
+
+// parseTimestamp parses the RFC 3339 timestamps returned by the API, e.g. 2021-05-20T09:19:00.000Z
+func parseTimestamp(ts string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, ts)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q: %w", ts, err)
+	}
+	return t, nil
+}
+
+// defaultPageSize is the page size used by the helpers which page through all the results
+const defaultPageSize = 100
+
+// maxPageSize is the largest page size accepted by the API
+const maxPageSize = 100
+
 // Pagination represents a request pagination params
 //
+// Zero PageSize isn't sent, the API uses its default page size of 100 then.
+//
 // See https://developers.notion.com/reference/pagination
 type Pagination struct {
 	StartCursor string
@@ -117,10 +412,11 @@ func (p *Pagination) query() map[string]string {
 	if p == nil {
 		return nil
 	}
-	query := map[string]string{
-		"page_size": strconv.Itoa(p.PageSize),
-	}
+	query := map[string]string{}
 
+	if p.PageSize != 0 {
+		query["page_size"] = strconv.Itoa(p.PageSize)
+	}
 	if p.StartCursor != "" {
 		query["start_cursor"] = p.StartCursor
 	}
@@ -128,10 +424,60 @@ func (p *Pagination) query() map[string]string {
 	return query
 }
 
-// Error represents an error returned by the API
+// validate checks the page size is within the bounds accepted by the API, zero means the default
+func (p *Pagination) validate() error {
+	if p == nil {
+		return nil
+	}
+	if p.PageSize < 0 || p.PageSize > maxPageSize {
+		return ClientError{Reason: fmt.Sprintf("invalid page size %d, want at most %d", p.PageSize, maxPageSize)}
+	}
+	return nil
+}
+
+// Paginated is implemented by the paginated lists returned by the API, e.g. PageList or DatabaseList
+//
+// See https://developers.notion.com/reference/pagination
+type Paginated interface {
+	More() bool
+	Cursor() string
+}
+
+// paginate calls fetch with the consecutive cursors until there are no more results
 //
-// See https://developers.notion.com/reference/errors
-type Error struct {
-	Code    string `json:"code,omitempty"`
-	Message string `json:"message,omitempty"`
+// The context is checked between the calls. At most maxPages are fetched if it's positive, ErrMaxPagesExceeded is
+// returned once the limit is hit.
+func paginate(ctx context.Context, maxPages int, fetch func(cursor string) (Paginated, error)) error {
+	cursor := ""
+	for fetched := 0; ; fetched++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if maxPages > 0 && fetched >= maxPages {
+			return ErrMaxPagesExceeded
+		}
+		result, err := fetch(cursor)
+		if err != nil {
+			return err
+		}
+		if !result.More() {
+			return nil
+		}
+		cursor = result.Cursor()
+	}
+}
+
+// Bool returns a pointer to b, e.g. to set an explicit false in a filter condition
+func Bool(b bool) *bool {
+	return &b
+}
+
+// Float64 returns a pointer to f, e.g. to set an explicit zero in a number property value or filter condition
+func Float64(f float64) *float64 {
+	return &f
+}
+
+// String returns a pointer to s, e.g. to set an explicit empty url in a property value
+func String(s string) *string {
+	return &s
 }
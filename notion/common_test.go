@@ -0,0 +1,341 @@
+package notion
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRichText_With(t *testing.T) {
+	plain := RichText{Type: "text", Text: &Text{Content: "Lacinato kale"}}
+	bold := plain.WithBold()
+
+	got := bold.WithItalic().WithColor(ColorGreen)
+
+	want := RichText{
+		Type: "text",
+		Text: &Text{Content: "Lacinato kale"},
+		Annotations: &Annotations{
+			Bold:   true,
+			Italic: true,
+			Color:  "green",
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("WithBold().WithItalic().WithColor() mismatch (-want +got):\n%s", diff)
+	}
+	if plain.Annotations != nil {
+		t.Errorf("original annotations = %+v, want <nil>", plain.Annotations)
+	}
+	if wantBold := (&Annotations{Bold: true}); !cmp.Equal(wantBold, bold.Annotations) {
+		t.Errorf("intermediate annotations = %+v, want %+v", bold.Annotations, wantBold)
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	rt := []RichText{
+		{Type: "text", Text: &Text{Content: "Write "}, PlainText: "Write "},
+		{
+			Type:        "text",
+			Text:        &Text{Content: "more"},
+			Annotations: &Annotations{Bold: true, Color: "red"},
+			PlainText:   "more",
+		},
+		{
+			Type:      "text",
+			Text:      &Text{Content: " integrations tests"},
+			PlainText: " integrations tests",
+			Href:      "https://example.com",
+		},
+	}
+	want := "Write more integrations tests"
+
+	if got := PlainText(rt); got != want {
+		t.Errorf("PlainText() = %q, want %q", got, want)
+	}
+	if got := (PropertyValue{Type: "title", Title: rt}).PlainText(); got != want {
+		t.Errorf("PropertyValue{title}.PlainText() = %q, want %q", got, want)
+	}
+	if got := (PropertyValue{Type: "rich_text", RichText: rt}).PlainText(); got != want {
+		t.Errorf("PropertyValue{rich_text}.PlainText() = %q, want %q", got, want)
+	}
+	if got := (PropertyValue{Type: "checkbox", Checkbox: true}).PlainText(); got != "" {
+		t.Errorf("PropertyValue{checkbox}.PlainText() = %q, want empty", got)
+	}
+}
+
+func TestNewText_Marshal(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{
+			name:  "should build a plain text",
+			value: NewText("Lacinato kale"),
+			want:  `{"type":"text","text":{"content":"Lacinato kale"}}`,
+		},
+		{
+			name:  "should build an annotated text",
+			value: NewAnnotatedText("Lacinato kale", Annotations{Bold: true, Color: "green"}),
+			want:  `{"type":"text","text":{"content":"Lacinato kale"},"annotations":{"bold":true,"color":"green"}}`,
+		},
+		{
+			name:  "should build a linked text",
+			value: NewLinkedText("Notion API", "https://developers.notion.com"),
+			want:  `{"type":"text","text":{"content":"Notion API","link":{"url":"https://developers.notion.com"}}}`,
+		},
+		{
+			name:  "should build a title property value",
+			value: TitleValue("Buy milk"),
+			want:  `{"type":"title","title":[{"type":"text","text":{"content":"Buy milk"}}]}`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.value)
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("json.Marshal() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRichText_Unmarshal(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want RichText
+	}{
+		{
+			name: "should decode a text link",
+			body: `{
+			  "type": "text",
+			  "text": {"content": "Notion API", "link": {"url": "https://developers.notion.com"}},
+			  "plain_text": "Notion API",
+			  "href": "https://developers.notion.com"
+			}`,
+			want: RichText{
+				Type:      "text",
+				Text:      &Text{Content: "Notion API", Link: &TextLink{URL: "https://developers.notion.com"}},
+				PlainText: "Notion API",
+				Href:      "https://developers.notion.com",
+			},
+		},
+		{
+			name: "should fill href from the text link",
+			body: `{
+			  "type": "text",
+			  "text": {"content": "Notion API", "link": {"url": "https://developers.notion.com"}},
+			  "plain_text": "Notion API",
+			  "href": null
+			}`,
+			want: RichText{
+				Type:      "text",
+				Text:      &Text{Content: "Notion API", Link: &TextLink{URL: "https://developers.notion.com"}},
+				PlainText: "Notion API",
+				Href:      "https://developers.notion.com",
+			},
+		},
+		{
+			name: "should decode a user mention",
+			body: `{
+			  "type": "mention",
+			  "mention": {
+				"type": "user",
+				"user": {"object": "user", "id": "e79a0b74-3aba-4149-9f74-0bb5791a6ee6", "name": "Igor", "type": "person"}
+			  },
+			  "plain_text": "@Igor",
+			  "href": null
+			}`,
+			want: RichText{
+				Type: "mention",
+				Mention: &Mention{
+					Type: "user",
+					User: &User{Object: "user", ID: "e79a0b74-3aba-4149-9f74-0bb5791a6ee6", Name: "Igor", Type: "person"},
+				},
+				PlainText: "@Igor",
+			},
+		},
+		{
+			name: "should decode a date mention",
+			body: `{
+			  "type": "mention",
+			  "mention": {"type": "date", "date": {"start": "2021-05-20", "end": null}},
+			  "plain_text": "2021-05-20",
+			  "href": null
+			}`,
+			want: RichText{
+				Type:      "mention",
+				Mention:   &Mention{Type: "date", Date: &DatePropertyValue{Start: "2021-05-20"}},
+				PlainText: "2021-05-20",
+			},
+		},
+		{
+			name: "should decode a page mention",
+			body: `{
+			  "type": "mention",
+			  "mention": {"type": "page", "page": {"id": "251d2b5f-268c-4de2-afe9-c71ff92ca95c"}},
+			  "plain_text": "Buy milk",
+			  "href": "https://www.notion.so/251d2b5f268c4de2afe9c71ff92ca95c"
+			}`,
+			want: RichText{
+				Type:      "mention",
+				Mention:   &Mention{Type: "page", Page: &ObjectReference{ID: "251d2b5f-268c-4de2-afe9-c71ff92ca95c"}},
+				PlainText: "Buy milk",
+				Href:      "https://www.notion.so/251d2b5f268c4de2afe9c71ff92ca95c",
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var got RichText
+			if err := json.Unmarshal([]byte(tt.body), &got); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("json.Unmarshal() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPagination_query(t *testing.T) {
+	tests := []struct {
+		name string
+		page *Pagination
+		want map[string]string
+	}{
+		{
+			name: "should not send an unset page size",
+			page: &Pagination{},
+			want: map[string]string{},
+		},
+		{
+			name: "should send a page size of 1",
+			page: &Pagination{PageSize: 1},
+			want: map[string]string{"page_size": "1"},
+		},
+		{
+			name: "should send a page size of 100",
+			page: &Pagination{PageSize: 100, StartCursor: "cursor-2"},
+			want: map[string]string{"page_size": "100", "start_cursor": "cursor-2"},
+		},
+		{
+			name: "should send nothing without pagination",
+			page: nil,
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, tt.page.query()); diff != "" {
+				t.Errorf("query() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestColor(t *testing.T) {
+	tests := []struct {
+		color Color
+		want  string
+	}{
+		{ColorDefault, "default"},
+		{ColorGray, "gray"},
+		{ColorBrown, "brown"},
+		{ColorOrange, "orange"},
+		{ColorYellow, "yellow"},
+		{ColorGreen, "green"},
+		{ColorBlue, "blue"},
+		{ColorPurple, "purple"},
+		{ColorPink, "pink"},
+		{ColorRed, "red"},
+		{ColorGrayBackground, "gray_background"},
+		{ColorBrownBackground, "brown_background"},
+		{ColorOrangeBackground, "orange_background"},
+		{ColorYellowBackground, "yellow_background"},
+		{ColorGreenBackground, "green_background"},
+		{ColorBlueBackground, "blue_background"},
+		{ColorPurpleBackground, "purple_background"},
+		{ColorPinkBackground, "pink_background"},
+		{ColorRedBackground, "red_background"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.want, func(t *testing.T) {
+			if string(tt.color) != tt.want {
+				t.Errorf("color = %q, want %q", tt.color, tt.want)
+			}
+
+			encoded, err := json.Marshal(SelectOption{Name: "Option", Color: tt.color})
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			wantJSON := `{"name":"Option","color":"` + tt.want + `"}`
+			if string(encoded) != wantJSON {
+				t.Errorf("json.Marshal() = %s, want %s", encoded, wantJSON)
+			}
+			var decoded Annotations
+			if err := json.Unmarshal([]byte(`{"color":"`+tt.want+`"}`), &decoded); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			if decoded.Color != tt.color {
+				t.Errorf("json.Unmarshal() color = %q, want %q", decoded.Color, tt.color)
+			}
+		})
+	}
+}
+
+func TestDateValue(t *testing.T) {
+	cet := time.FixedZone("CET", 60*60)
+	end := time.Date(2021, 5, 12, 18, 0, 0, 0, cet)
+	tests := []struct {
+		name  string
+		start time.Time
+		end   *time.Time
+		want  PropertyValue
+		body  string
+	}{
+		{
+			name:  "date only",
+			start: time.Date(2021, 5, 12, 0, 0, 0, 0, time.UTC),
+			want:  PropertyValue{Type: "date", Date: &DatePropertyValue{Start: "2021-05-12"}},
+			body:  `{"type":"date","date":{"start":"2021-05-12"}}`,
+		},
+		{
+			name:  "date time with end",
+			start: time.Date(2021, 5, 12, 9, 30, 0, 0, cet),
+			end:   &end,
+			want: PropertyValue{
+				Type: "date",
+				Date: &DatePropertyValue{Start: "2021-05-12T09:30:00+01:00", End: "2021-05-12T18:00:00+01:00"},
+			},
+			body: `{"type":"date","date":{"start":"2021-05-12T09:30:00+01:00","end":"2021-05-12T18:00:00+01:00"}}`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got := DateValue(tt.start, tt.end)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("DateValue() mismatch (-want +got):\n%s", diff)
+			}
+			encoded, err := json.Marshal(got)
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			if string(encoded) != tt.body {
+				t.Errorf("json.Marshal() = %s, want %s", encoded, tt.body)
+			}
+		})
+	}
+}
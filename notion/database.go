@@ -1,9 +1,19 @@
 package notion
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"notion-go/client"
 )
 
 // Database represents a notion database
@@ -18,6 +28,138 @@ type Database struct {
 	Properties     map[string]Property `json:"properties,omitempty"`
 }
 
+// CreatedAt parses the CreatedTime of the database
+func (d *Database) CreatedAt() (time.Time, error) {
+	return parseTimestamp(d.CreatedTime)
+}
+
+// LastEditedAt parses the LastEditedTime of the database
+func (d *Database) LastEditedAt() (time.Time, error) {
+	return parseTimestamp(d.LastEditedTime)
+}
+
+// HasMoreProperties reports whether the pages of the database may hold property values truncated in the page object
+//
+// It's a heuristic based on the schema: the relation, rollup and people properties list other objects and their
+// values are truncated past 25 of them, see Page.HasMoreProperties for the check of a single page. Use
+// RetrievePageProperty to fetch the complete value.
+func (d *Database) HasMoreProperties() bool {
+	for _, p := range d.Properties {
+		if truncatingTypes[p.Type] {
+			return true
+		}
+	}
+	return false
+}
+
+// clone returns a copy of the database which doesn't share the Title and the Properties with d
+func (d *Database) clone() *Database {
+	cp := *d
+	if d.Title != nil {
+		cp.Title = append([]RichText{}, d.Title...)
+	}
+	if d.Properties != nil {
+		cp.Properties = make(map[string]Property, len(d.Properties))
+		for name, p := range d.Properties {
+			cp.Properties[name] = p
+		}
+	}
+	return &cp
+}
+
+// PropertyIDByName returns the ID of the database property with the given name
+//
+// Unlike the names, the property IDs don't change when the columns are renamed, so they make for durable references in
+// filters and sorts.
+func (d *Database) PropertyIDByName(name string) (string, error) {
+	prop, ok := d.Properties[name]
+	if !ok {
+		return "", fmt.Errorf("database %s has no property %s", d.ID, name)
+	}
+	return prop.ID, nil
+}
+
+// NewPageProperties returns a skeleton of the properties of a page in the database, to fill in before creating the page
+//
+// Each property gets an empty value of its type. The read-only properties, e.g. created_time, are left out.
+func (d *Database) NewPageProperties() map[string]PropertyValue {
+	props := make(map[string]PropertyValue, len(d.Properties))
+	for name, prop := range d.Properties {
+		if readOnlyTypes[prop.Type] {
+			continue
+		}
+		props[name] = PropertyValue{Type: prop.Type}
+	}
+	return props
+}
+
+// SchemaError lists the problems found when validating a request against the database schema
+type SchemaError struct {
+	Problems []string
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("schema validation failed: %s", strings.Join(e.Problems, "; "))
+}
+
+// ValidateCreate checks the properties of the page to create against the database schema
+//
+// It verifies that every property exists in the database, has the matching type (if set) and that the select and
+// multi-select values are among the known options. All the problems found are reported in a single SchemaError.
+func (d *Database) ValidateCreate(req CreatePageRequest) error {
+	names := make([]string, 0, len(req.Properties))
+	for name := range req.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var problems []string
+	for _, name := range names {
+		pv := req.Properties[name]
+		prop, ok := d.Properties[name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("unknown property %q", name))
+			continue
+		}
+		if pv.Type != "" && pv.Type != prop.Type {
+			problems = append(problems, fmt.Sprintf("property %q is of type %q, got %q", name, prop.Type, pv.Type))
+			continue
+		}
+		if pv.Select != nil && prop.Select != nil && !hasSelectOption(prop.Select.Options, pv.Select.ID, pv.Select.Name) {
+			problems = append(problems, fmt.Sprintf("property %q has no option %q", name, pv.Select.Name))
+		}
+		if prop.MultiSelect != nil {
+			for _, value := range pv.MultiSelect {
+				if !hasMultiSelectOption(prop.MultiSelect.Options, value.ID, value.Name) {
+					problems = append(problems, fmt.Sprintf("property %q has no option %q", name, value.Name))
+				}
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return &SchemaError{Problems: problems}
+	}
+	return nil
+}
+
+func hasSelectOption(options []SelectOption, id, name string) bool {
+	for _, option := range options {
+		if (id != "" && option.ID == id) || (id == "" && option.Name == name) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasMultiSelectOption(options []MultiSelectOption, id, name string) bool {
+	for _, option := range options {
+		if (id != "" && option.ID == id) || (id == "" && option.Name == name) {
+			return true
+		}
+	}
+	return false
+}
+
 // PageList is a response to the query database endpoint
 //
 // See https://developers.notion.com/reference/post-database-query
@@ -25,11 +167,41 @@ type Database struct {
 // See https://developers.notion.com/reference/pagination
 type PageList struct {
 	Object     string
+	Type       string `json:"type,omitempty"`
 	Results    []Page `json:"results,omitempty"`
 	NextCursor string `json:"next_cursor,omitempty"`
 	HasMore    bool   `json:"has_more,omitempty"`
 }
 
+// EnvelopeType returns the type of the items the list holds, e.g. "page" for the database query results
+//
+// It's empty if the API version in use doesn't report the list type.
+func (l *PageList) EnvelopeType() string {
+	return l.Type
+}
+
+// UnmarshalJSON decodes the list, null results are decoded as an empty slice
+func (l *PageList) UnmarshalJSON(data []byte) error {
+	type list PageList
+	if err := json.Unmarshal(data, (*list)(l)); err != nil {
+		return err
+	}
+	if l.Results == nil {
+		l.Results = []Page{}
+	}
+	return nil
+}
+
+// More reports whether there are more results to fetch
+func (l *PageList) More() bool {
+	return l.HasMore
+}
+
+// Cursor returns the cursor of the next page of results, see Pagination.StartCursor
+func (l *PageList) Cursor() string {
+	return l.NextCursor
+}
+
 // DatabaseList is a response to list databases endpoint
 //
 // See https://developers.notion.com/reference/get-databases
@@ -40,52 +212,479 @@ type DatabaseList struct {
 	Results    []Database `json:"results,omitempty"`
 }
 
+// UnmarshalJSON decodes the list, null results are decoded as an empty slice
+func (l *DatabaseList) UnmarshalJSON(data []byte) error {
+	type list DatabaseList
+	if err := json.Unmarshal(data, (*list)(l)); err != nil {
+		return err
+	}
+	if l.Results == nil {
+		l.Results = []Database{}
+	}
+	return nil
+}
+
+// More reports whether there are more results to fetch
+func (l *DatabaseList) More() bool {
+	return l.HasMore
+}
+
+// Cursor returns the cursor of the next page of results, see Pagination.StartCursor
+func (l *DatabaseList) Cursor() string {
+	return l.NextCursor
+}
+
 // Filter describes conditions on page property values to include in the results from a database query
 //
+// Property is either the name or the ID of the property, see FilterByPropertyID. To filter on the page timestamp
+// instead, set Timestamp to "created_time" or "last_edited_time" and the condition in the matching field.
+//
 // See also https://developers.notion.com/reference/post-database-query#post-database-query-filter
 type Filter struct {
-	Property string                   `json:"property,omitempty"`
-	Checkbox *CheckboxFilterCondition `json:"checkbox,omitempty"`
+	*CompoundFilter
+	Property       string                   `json:"property,omitempty"`
+	Timestamp      string                   `json:"timestamp,omitempty"`
+	Title          *TextFilterCondition     `json:"title,omitempty"`
+	RichText       *TextFilterCondition     `json:"rich_text,omitempty"`
+	Checkbox       *CheckboxFilterCondition `json:"checkbox,omitempty"`
+	Select         *SelectFilterCondition   `json:"select,omitempty"`
+	Number         *NumberFilterCondition   `json:"number,omitempty"`
+	Date           *DateFilterCondition     `json:"date,omitempty"`
+	CreatedTime    *DateFilterCondition     `json:"created_time,omitempty"`
+	LastEditedTime *DateFilterCondition     `json:"last_edited_time,omitempty"`
+	Status         *StatusFilterCondition   `json:"status,omitempty"`
+	Relation       *RelationFilterCondition `json:"relation,omitempty"`
 	// TODO: add more filter types
 }
 
+// FilterByPropertyID starts a filter on the property with the given ID, set the condition on the returned filter
+//
+// Use Database.PropertyIDByName to find the ID.
+func FilterByPropertyID(propertyID string) *Filter {
+	return &Filter{Property: propertyID}
+}
+
+// CompoundFilter combines other filters, a page matches if it matches all the And filters, or any of the Or filters
+//
+// Set it on a Filter to query a database with it, e.g. &Filter{CompoundFilter: compound}.
+//
+// See also https://developers.notion.com/reference/post-database-query#compound-filter-object
+type CompoundFilter struct {
+	And []Filter `json:"and,omitempty"`
+	Or  []Filter `json:"or,omitempty"`
+}
+
+// titlePropertyID is the ID of the title property, the same in every database
+const titlePropertyID = "title"
+
+// TitleFilter builds a filter matching the pages with the given title
+//
+// It refers to the title property by its ID, so it works regardless of how the property is named. To match the title
+// in other ways, set the condition on a Filter with the Title condition, using either the name or the "title" ID.
+func TitleFilter(value string) *Filter {
+	return &Filter{Property: titlePropertyID, Title: &TextFilterCondition{Equals: value}}
+}
+
+// DueToday builds a filter matching the pages with the date property falling on the current day in the given location
+//
+// The day spans from the midnight (inclusive) to the next midnight (exclusive) in loc.
+func DueToday(property string, loc *time.Location) *CompoundFilter {
+	return dueToday(property, time.Now().In(loc))
+}
+
+func dueToday(property string, now time.Time) *CompoundFilter {
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	end := start.AddDate(0, 0, 1)
+	return &CompoundFilter{
+		And: []Filter{
+			{Property: property, Date: &DateFilterCondition{OnOrAfter: start.Format(time.RFC3339)}},
+			{Property: property, Date: &DateFilterCondition{Before: end.Format(time.RFC3339)}},
+		},
+	}
+}
+
+// TextFilterCondition applies to database properties of types "title" and "rich_text".
+//
+// See also https://developers.notion.com/reference/post-database-query#text-filter-condition
+type TextFilterCondition struct {
+	Equals         string `json:"equals,omitempty"`
+	DoesNotEqual   string `json:"does_not_equal,omitempty"`
+	Contains       string `json:"contains,omitempty"`
+	DoesNotContain string `json:"does_not_contain,omitempty"`
+	StartsWith     string `json:"starts_with,omitempty"`
+	EndsWith       string `json:"ends_with,omitempty"`
+	IsEmpty        bool   `json:"is_empty,omitempty"`
+	IsNotEmpty     bool   `json:"is_not_empty,omitempty"`
+}
+
 // CheckboxFilterCondition applies to database properties of type "checkbox".
 //
+// The values are pointers so that a comparison with false can be told from an unset condition, see Bool.
+//
 // See also https://developers.notion.com/reference/post-database-query#checkbox-filter-condition
 type CheckboxFilterCondition struct {
-	Equals       bool `json:"equals,omitempty"`
-	DoesNotEqual bool `json:"does_not_equal,omitempty"`
+	Equals       *bool `json:"equals,omitempty"`
+	DoesNotEqual *bool `json:"does_not_equal,omitempty"`
+}
+
+// StatusFilterCondition applies to database properties of type "status", the values are the option names.
+//
+// See also https://developers.notion.com/reference/post-database-query-filter#status
+type StatusFilterCondition struct {
+	Equals       string `json:"equals,omitempty"`
+	DoesNotEqual string `json:"does_not_equal,omitempty"`
+	IsEmpty      bool   `json:"is_empty,omitempty"`
+	IsNotEmpty   bool   `json:"is_not_empty,omitempty"`
+}
+
+// RelationFilterCondition applies to database properties of type "relation", the values are the related page IDs.
+//
+// See also https://developers.notion.com/reference/post-database-query#relation-filter-condition
+type RelationFilterCondition struct {
+	Contains       string `json:"contains,omitempty"`
+	DoesNotContain string `json:"does_not_contain,omitempty"`
+	IsEmpty        bool   `json:"is_empty,omitempty"`
+	IsNotEmpty     bool   `json:"is_not_empty,omitempty"`
+}
+
+// SelectFilterCondition applies to database properties of type "select", the values are the option names.
+//
+// See also https://developers.notion.com/reference/post-database-query#select-filter-condition
+type SelectFilterCondition struct {
+	Equals       string `json:"equals,omitempty"`
+	DoesNotEqual string `json:"does_not_equal,omitempty"`
+	IsEmpty      bool   `json:"is_empty,omitempty"`
+	IsNotEmpty   bool   `json:"is_not_empty,omitempty"`
+}
+
+// NumberFilterCondition applies to database properties of type "number".
+//
+// The values are pointers so that a comparison with zero can be told from an unset condition.
+//
+// See also https://developers.notion.com/reference/post-database-query#number-filter-condition
+type NumberFilterCondition struct {
+	Equals               *float64 `json:"equals,omitempty"`
+	DoesNotEqual         *float64 `json:"does_not_equal,omitempty"`
+	GreaterThan          *float64 `json:"greater_than,omitempty"`
+	LessThan             *float64 `json:"less_than,omitempty"`
+	GreaterThanOrEqualTo *float64 `json:"greater_than_or_equal_to,omitempty"`
+	LessThanOrEqualTo    *float64 `json:"less_than_or_equal_to,omitempty"`
+	IsEmpty              bool     `json:"is_empty,omitempty"`
+	IsNotEmpty           bool     `json:"is_not_empty,omitempty"`
 }
 
+// DateFilterCondition applies to database properties of types "date", "created_time", and "last_edited_time".
+//
+// The absolute conditions take an ISO 8601 date or date-time string. The relative conditions (PastWeek, NextMonth, ...)
+// are flags, they are sent as empty objects when set.
+//
+// See also https://developers.notion.com/reference/post-database-query#date-filter-condition
+type DateFilterCondition struct {
+	Equals     string `json:"equals,omitempty"`
+	Before     string `json:"before,omitempty"`
+	After      string `json:"after,omitempty"`
+	OnOrBefore string `json:"on_or_before,omitempty"`
+	OnOrAfter  string `json:"on_or_after,omitempty"`
+	IsEmpty    bool   `json:"is_empty,omitempty"`
+	IsNotEmpty bool   `json:"is_not_empty,omitempty"`
+	PastWeek   bool   `json:"-"`
+	PastMonth  bool   `json:"-"`
+	PastYear   bool   `json:"-"`
+	NextWeek   bool   `json:"-"`
+	NextMonth  bool   `json:"-"`
+	NextYear   bool   `json:"-"`
+}
+
+// MarshalJSON encodes the relative conditions as empty objects, e.g. {"past_week":{}}
+func (c DateFilterCondition) MarshalJSON() ([]byte, error) {
+	type condition DateFilterCondition
+	return json.Marshal(struct {
+		condition
+		PastWeek  *struct{} `json:"past_week,omitempty"`
+		PastMonth *struct{} `json:"past_month,omitempty"`
+		PastYear  *struct{} `json:"past_year,omitempty"`
+		NextWeek  *struct{} `json:"next_week,omitempty"`
+		NextMonth *struct{} `json:"next_month,omitempty"`
+		NextYear  *struct{} `json:"next_year,omitempty"`
+	}{
+		condition: condition(c),
+		PastWeek:  emptyObject(c.PastWeek),
+		PastMonth: emptyObject(c.PastMonth),
+		PastYear:  emptyObject(c.PastYear),
+		NextWeek:  emptyObject(c.NextWeek),
+		NextMonth: emptyObject(c.NextMonth),
+		NextYear:  emptyObject(c.NextYear),
+	})
+}
+
+// UnmarshalJSON decodes the relative conditions from empty objects, the reverse of MarshalJSON
+func (c *DateFilterCondition) UnmarshalJSON(data []byte) error {
+	type condition DateFilterCondition
+	var v struct {
+		condition
+		PastWeek  *struct{} `json:"past_week"`
+		PastMonth *struct{} `json:"past_month"`
+		PastYear  *struct{} `json:"past_year"`
+		NextWeek  *struct{} `json:"next_week"`
+		NextMonth *struct{} `json:"next_month"`
+		NextYear  *struct{} `json:"next_year"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*c = DateFilterCondition(v.condition)
+	c.PastWeek = v.PastWeek != nil
+	c.PastMonth = v.PastMonth != nil
+	c.PastYear = v.PastYear != nil
+	c.NextWeek = v.NextWeek != nil
+	c.NextMonth = v.NextMonth != nil
+	c.NextYear = v.NextYear != nil
+	return nil
+}
+
+func emptyObject(set bool) *struct{} {
+	if !set {
+		return nil
+	}
+	return &struct{}{}
+}
+
+// SortDirection is the order of a Sort
+type SortDirection string
+
 const (
-	SortAsc  = "ascending"
-	SortDesc = "descending"
+	SortAsc  SortDirection = "ascending"
+	SortDesc SortDirection = "descending"
 )
 
 // Sort objects describe the order of database query results
 //
+// Property is either the name or the ID of the property to sort by.
+//
 // See also https://developers.notion.com/reference/post-database-query (bottom of the page)
 type Sort struct {
-	Property  string `json:"property,omitempty"`
-	Timestamp string `json:"timestamp,omitempty"`
-	Direction string `json:"direction,omitempty"`
+	Property  string        `json:"property,omitempty"`
+	Timestamp string        `json:"timestamp,omitempty"`
+	Direction SortDirection `json:"direction,omitempty"`
+}
+
+// SortBuilder builds the sorts for a database query, the sorts added first take precedence
+//
+// It can be passed wherever a []Sort is expected:
+//
+//	sorts := SortBuilder{}.ByProperty("Status", SortAsc).ByTimestamp("created_time", SortDesc)
+type SortBuilder []Sort
+
+// ByProperty adds a sort by the given property name or ID
+func (b SortBuilder) ByProperty(name string, dir SortDirection) SortBuilder {
+	return b.add(Sort{Property: name, Direction: dir})
+}
+
+// ByTimestamp adds a sort by the "created_time" or "last_edited_time" timestamp of the pages
+func (b SortBuilder) ByTimestamp(ts string, dir SortDirection) SortBuilder {
+	return b.add(Sort{Timestamp: ts, Direction: dir})
+}
+
+// add returns a copy with the sort appended, so that builders derived from a common one don't share the sorts
+func (b SortBuilder) add(next Sort) SortBuilder {
+	sorts := make(SortBuilder, len(b), len(b)+1)
+	copy(sorts, b)
+	return append(sorts, next)
+}
+
+// validate checks that the sort has a property or a timestamp to sort by and a known direction
+func (s Sort) validate() error {
+	if s.Property == "" && s.Timestamp == "" {
+		return ClientError{Reason: "sort needs either a property or a timestamp"}
+	}
+	if s.Direction != "" && s.Direction != SortAsc && s.Direction != SortDesc {
+		return ClientError{Reason: fmt.Sprintf("invalid sort direction %q, want %q or %q", s.Direction, SortAsc, SortDesc)}
+	}
+	return nil
 }
 
 // RetrieveDatabase retrieves a Database object using the ID specified
 //
+// The databases are cached along with the ETag of the response, if any. The next retrieve of the database is
+// a conditional request, the cached copy is returned if the database hasn't changed. The cache is shared with the
+// copies made by Service.With and it's unbounded, it keeps the last version of every database retrieved.
+//
 // See https://developers.notion.com/reference/get-database
 func (s *Service) RetrieveDatabase(ctx context.Context, databaseID string) (*Database, error) {
+	return s.retrieveDatabase(ctx, databaseID, &client.ResponseMeta{})
+}
+
+// RetrieveDatabaseWithResponse retrieves a Database object like RetrieveDatabase, it also returns the metadata of the
+// response, e.g. the request id to quote in a support ticket
+//
+// The metadata is returned on an API error as well, it's nil if there was no response.
+func (s *Service) RetrieveDatabaseWithResponse(
+	ctx context.Context,
+	databaseID string,
+) (*Database, *client.ResponseMeta, error) {
+	meta := &client.ResponseMeta{}
+	db, err := s.retrieveDatabase(ctx, databaseID, meta)
+	if meta.StatusCode == 0 {
+		meta = nil
+	}
+	return db, meta, err
+}
+
+func (s *Service) retrieveDatabase(ctx context.Context, databaseID string, meta *client.ResponseMeta) (*Database, error) {
+	databaseID, err := normalizeID(databaseID)
+	if err != nil {
+		return nil, err
+	}
+	ctx = client.WithResponseMeta(ctx, meta)
+	cached, ok := s.databases.get(databaseID)
+	if ok {
+		ctx = client.WithRequestHeader(ctx, "If-None-Match", cached.etag)
+	}
 	db := &Database{}
 	apiErr := &Error{}
-	if err := s.client.Do(ctx, http.MethodGet, fmt.Sprintf("/databases/%s", databaseID), nil, nil, db, apiErr); err != nil {
+	err = s.client.Do(ctx, http.MethodGet, fmt.Sprintf("/databases/%s", databaseID), nil, nil, db, apiErr)
+	if errors.Is(err, client.ErrNotModified) && ok {
+		return cached.db.clone(), nil
+	}
+	if err != nil {
 		return nil, err
 	}
+	if etag := meta.Header.Get("ETag"); etag != "" {
+		s.databases.put(databaseID, etag, db.clone())
+	}
 	return db, nil
 }
 
+// databaseCache holds the last retrieved databases along with their ETags, it's safe for concurrent use
+//
+// Nothing is ever evicted, the entries are bounded by the number of databases shared with the integration.
+type databaseCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedDatabase
+}
+
+type cachedDatabase struct {
+	etag string
+	db   *Database
+}
+
+func newDatabaseCache() *databaseCache {
+	return &databaseCache{entries: map[string]cachedDatabase{}}
+}
+
+func (c *databaseCache) get(databaseID string) (cachedDatabase, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[databaseID]
+	return entry, ok
+}
+
+func (c *databaseCache) put(databaseID, etag string, db *Database) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[databaseID] = cachedDatabase{etag: etag, db: db}
+}
+
+// CreateDatabase creates a database as a child of the given parent page
+//
+// The properties describe the database schema, one of them needs to be a title property.
+//
+// See https://developers.notion.com/reference/create-a-database
+func (s *Service) CreateDatabase(
+	ctx context.Context,
+	parent Parent,
+	title []RichText,
+	properties map[string]Property,
+) (*Database, error) {
+	type Payload struct {
+		Parent     Parent              `json:"parent"`
+		Title      []RichText          `json:"title,omitempty"`
+		Properties map[string]Property `json:"properties"`
+	}
+	db := &Database{}
+	apiErr := &Error{}
+	if err := s.client.Do(
+		ctx,
+		http.MethodPost,
+		"/databases",
+		nil,
+		&Payload{Parent: parent, Title: title, Properties: properties},
+		db,
+		apiErr,
+	); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// UpdateDatabase changes the title and the schema of the given database
+//
+// A nil title or nil properties are left unchanged. The properties not listed are left unchanged as well.
+//
+// See https://developers.notion.com/reference/update-a-database
+func (s *Service) UpdateDatabase(
+	ctx context.Context,
+	databaseID string,
+	title []RichText,
+	properties map[string]Property,
+) (*Database, error) {
+	databaseID, err := normalizeID(databaseID)
+	if err != nil {
+		return nil, err
+	}
+	type Payload struct {
+		Title      []RichText          `json:"title,omitempty"`
+		Properties map[string]Property `json:"properties,omitempty"`
+	}
+	db := &Database{}
+	apiErr := &Error{}
+	if err := s.client.Do(
+		ctx,
+		http.MethodPatch,
+		fmt.Sprintf("/databases/%s", databaseID),
+		nil,
+		&Payload{Title: title, Properties: properties},
+		db,
+		apiErr,
+	); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// EnsureSelectOption adds the option to the select property of the database unless the property already has it
+//
+// The existing options are kept. It takes two requests if the option is missing, so concurrent calls can race.
+func (s *Service) EnsureSelectOption(ctx context.Context, databaseID, propertyName, optionName string, color Color) error {
+	db, err := s.RetrieveDatabase(ctx, databaseID)
+	if err != nil {
+		return err
+	}
+	prop, ok := db.Properties[propertyName]
+	if !ok {
+		return fmt.Errorf("database %s has no property %s", db.ID, propertyName)
+	}
+	if prop.Type != "select" || prop.Select == nil {
+		return fmt.Errorf("property %s of database %s is of type %s, want select", propertyName, db.ID, prop.Type)
+	}
+	for _, option := range prop.Select.Options {
+		if option.Name == optionName {
+			return nil
+		}
+	}
+
+	options := append([]SelectOption{}, prop.Select.Options...)
+	options = append(options, SelectOption{Name: optionName, Color: color})
+	_, err = s.UpdateDatabase(ctx, databaseID, nil, map[string]Property{
+		propertyName: {Select: &SelectProperty{Options: options}},
+	})
+	return err
+}
+
 // QueryDatabase returns a list of pages from the given database
 //
-// The pages are filtered per given criteria.
+// The pages are filtered per given criteria. The filter is checked before sending, each single property filter needs
+// exactly one condition.
 //
 // See https://developers.notion.com/reference/post-database-query#post-database-query-filter
 func (s *Service) QueryDatabase(
@@ -95,6 +694,23 @@ func (s *Service) QueryDatabase(
 	sorts []Sort,
 	pagination *Pagination,
 ) (*PageList, error) {
+	databaseID, err := normalizeID(databaseID)
+	if err != nil {
+		return nil, err
+	}
+	for _, by := range sorts {
+		if err := by.validate(); err != nil {
+			return nil, err
+		}
+	}
+	if filter != nil {
+		if err := filter.validate(); err != nil {
+			return nil, ClientError{Reason: "invalid filter", Inner: err}
+		}
+	}
+	if err := pagination.validate(); err != nil {
+		return nil, err
+	}
 	type Payload struct {
 		Filter      *Filter `json:"filter,omitempty"`
 		Sorts       []Sort  `json:"sorts,omitempty"`
@@ -127,10 +743,94 @@ func (s *Service) QueryDatabase(
 	return pages, nil
 }
 
+// QueryDatabaseBySelect queries the given database for the pages with the select property set to value
+//
+// It returns the first page of results like QueryDatabase does.
+func (s *Service) QueryDatabaseBySelect(
+	ctx context.Context,
+	databaseID string,
+	property string,
+	value string,
+	sorts []Sort,
+) (*PageList, error) {
+	filter := &Filter{Property: property, Select: &SelectFilterCondition{Equals: value}}
+	return s.QueryDatabase(ctx, databaseID, filter, sorts, nil)
+}
+
+// QueryDatabaseRaw is QueryDatabase with the request body, e.g. a filter not covered by Filter yet, sent verbatim
+//
+// The body is the whole query, e.g. {"filter":{...},"sorts":[...]}, it's only checked to be valid JSON.
+//
+// See https://developers.notion.com/reference/post-database-query
+func (s *Service) QueryDatabaseRaw(ctx context.Context, databaseID string, body json.RawMessage) (*PageList, error) {
+	databaseID, err := normalizeID(databaseID)
+	if err != nil {
+		return nil, err
+	}
+	if !json.Valid(body) {
+		return nil, ClientError{Reason: "invalid query body, want JSON"}
+	}
+	pages := &PageList{}
+	apiErr := &Error{}
+	if err := s.client.Do(
+		ctx,
+		http.MethodPost,
+		fmt.Sprintf("/databases/%s/query", databaseID),
+		nil,
+		rawBody(body),
+		pages,
+		apiErr,
+	); err != nil {
+		return nil, err
+	}
+	return pages, nil
+}
+
+// rawBody is a JSON request body sent as is
+type rawBody []byte
+
+func (b rawBody) ContentType() string {
+	return "application/json"
+}
+
+func (b rawBody) Encode() (io.Reader, error) {
+	return bytes.NewReader(b), nil
+}
+
+// QueryDatabaseAll returns all the pages from the given database matching the filter
+//
+// It pages through the results of QueryDatabase until there are no more pages left. The context is checked between
+// the requests, so a cancelled query doesn't fetch any further pages, the pages fetched so far are returned with the
+// context error then. Likewise, if the Service limits the number of result pages (see WithMaxPages), the pages fetched
+// so far are returned with ErrMaxPagesExceeded.
+func (s *Service) QueryDatabaseAll(ctx context.Context, databaseID string, filter *Filter, sorts []Sort) ([]Page, error) {
+	var pages []Page
+	err := paginate(ctx, s.maxPages, func(cursor string) (Paginated, error) {
+		result, err := s.QueryDatabase(ctx, databaseID, filter, sorts, &Pagination{StartCursor: cursor, PageSize: defaultPageSize})
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, result.Results...)
+		return result, nil
+	})
+	if err == nil || err == ErrMaxPagesExceeded {
+		return pages, err
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return pages, ctxErr
+	}
+	return nil, err
+}
+
 // ListDatabases lists all databases shared with the authenticated integration.
 //
+// The endpoint is deprecated, prefer ListDatabasesViaSearch.
+//
 // See https://developers.notion.com/reference/get-databases
 func (s *Service) ListDatabases(ctx context.Context, page Pagination) (*DatabaseList, error) {
+	if err := page.validate(); err != nil {
+		return nil, err
+	}
 	dbs := &DatabaseList{}
 	apiErr := &Error{}
 	if err := s.client.Do(ctx, http.MethodGet, "/databases", page.query(), nil, dbs, apiErr); err != nil {
@@ -138,3 +838,44 @@ func (s *Service) ListDatabases(ctx context.Context, page Pagination) (*Database
 	}
 	return dbs, nil
 }
+
+// ListDatabasesViaSearch lists the databases shared with the authenticated integration using the search endpoint
+//
+// It's the replacement for ListDatabases, whose endpoint is deprecated.
+//
+// See https://developers.notion.com/reference/post-search
+func (s *Service) ListDatabasesViaSearch(ctx context.Context, page Pagination) (*DatabaseList, error) {
+	if err := page.validate(); err != nil {
+		return nil, err
+	}
+	payload := newSearchPayload("", &SearchFilter{Property: "object", Value: "database"}, nil, page)
+	dbs := &DatabaseList{}
+	apiErr := &Error{}
+	if err := s.client.Do(ctx, http.MethodPost, "/search", nil, payload, dbs, apiErr); err != nil {
+		return nil, err
+	}
+	return dbs, nil
+}
+
+// ListDatabasesAll returns all the databases shared with the authenticated integration
+//
+// It pages through the results of ListDatabases like QueryDatabaseAll does, including the partial results on
+// cancellation and the WithMaxPages limit.
+func (s *Service) ListDatabasesAll(ctx context.Context) ([]Database, error) {
+	var dbs []Database
+	err := paginate(ctx, s.maxPages, func(cursor string) (Paginated, error) {
+		result, err := s.ListDatabases(ctx, Pagination{StartCursor: cursor, PageSize: defaultPageSize})
+		if err != nil {
+			return nil, err
+		}
+		dbs = append(dbs, result.Results...)
+		return result, nil
+	})
+	if err == nil || err == ErrMaxPagesExceeded {
+		return dbs, err
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return dbs, ctxErr
+	}
+	return nil, err
+}
@@ -3,33 +3,26 @@ package notion
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"notion-go/notiontest"
 )
 
 // RequestToResponse is a function which given the request produces a response or an error
-type RequestToResponse func(req *http.Request) (*http.Response, error)
-
-// RoundTrip method to implement http.RoundTripper interface
-func (f RequestToResponse) RoundTrip(req *http.Request) (*http.Response, error) {
-	return f(req)
-}
+type RequestToResponse = notiontest.RequestToResponse
 
 // Create a mock http.Client which instead of making an http call will use provided function to provide a response
 func RequestCapturingMockHttpClient(f RequestToResponse) (*http.Client, *http.Request) {
-	var capture http.Request
-	return &http.Client{
-		Transport: RequestToResponse(func(req *http.Request) (*http.Response, error) {
-			capture = *req.Clone(req.Context())
-			return f(req)
-		}),
-	}, &capture
+	return notiontest.NewCapturingClient(f)
 }
 
 func TestService_RetrieveDatabase(t *testing.T) {
@@ -237,16 +230,16 @@ func TestService_RetrieveDatabase(t *testing.T) {
 		},
 		{
 			name:           "should parse an error",
-			databaseID:     "not-uuid",
-			respStatusCode: 400,
+			databaseID:     "a1d8501e-1ac1-43e9-a6bd-ea9fe6c8822b",
+			respStatusCode: 404,
 			respBody: `{
 			  "object": "error",
-			  "status": 400,
-			  "code": "validation_error",
-			  "message": "The provided database ID is not a valid Notion UUID: e65ccf14-e13b-48d1-a6d1-b14cd84c4be."
+			  "status": 404,
+			  "code": "object_not_found",
+			  "message": "Could not find database with ID: a1d8501e-1ac1-43e9-a6bd-ea9fe6c8822b."
 			}`,
-			wantPath:   "/v1/databases/not-uuid",
-			wantErrMsg: "application error: &{validation_error The provided database ID is not a valid Notion UUID: e65ccf14-e13b-48d1-a6d1-b14cd84c4be.}",
+			wantPath:   "/v1/databases/a1d8501e-1ac1-43e9-a6bd-ea9fe6c8822b",
+			wantErrMsg: "application error: &{object_not_found Could not find database with ID: a1d8501e-1ac1-43e9-a6bd-ea9fe6c8822b.}",
 		},
 	}
 	for _, tt := range tests {
@@ -285,6 +278,138 @@ func TestService_RetrieveDatabase(t *testing.T) {
 	}
 }
 
+func TestService_RetrieveDatabaseWithResponse(t *testing.T) {
+	httpClient, _ := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 200,
+			Header:     http.Header{"X-Request-Id": []string{"8fd5a6f2-5a2c-4f5b-9d1c-2b1b3f0e7a11"}},
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"object":"database","id":"e65ccf14-e13b-48d1-a6d1-b14cd84c4bed"}`)),
+		}, nil
+	})
+	service := New("token", WithHTTPClient(httpClient))
+
+	gotDB, gotMeta, gotErr := service.RetrieveDatabaseWithResponse(context.Background(), "e65ccf14-e13b-48d1-a6d1-b14cd84c4bed")
+	if gotErr != nil {
+		t.Fatalf("RetrieveDatabaseWithResponse() error = %v, wantErr <nil>", gotErr)
+	}
+
+	if gotDB.ID != "e65ccf14-e13b-48d1-a6d1-b14cd84c4bed" {
+		t.Errorf("ID = %v, want e65ccf14-e13b-48d1-a6d1-b14cd84c4bed", gotDB.ID)
+	}
+	if gotMeta == nil {
+		t.Fatalf("meta = <nil>, want the response metadata")
+	}
+	if gotMeta.StatusCode != 200 {
+		t.Errorf("StatusCode = %d, want 200", gotMeta.StatusCode)
+	}
+	if want := "8fd5a6f2-5a2c-4f5b-9d1c-2b1b3f0e7a11"; gotMeta.RequestID != want {
+		t.Errorf("RequestID = %v, want %v", gotMeta.RequestID, want)
+	}
+}
+
+func TestService_CreateDatabase(t *testing.T) {
+	httpClient, capturedRequest := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"object":"database","id":"bc1211ca-e3f1-4939-ae34-5260b16f627c"}`)),
+		}, nil
+	})
+	service := New("token", WithHTTPClient(httpClient))
+
+	gotDB, gotErr := service.CreateDatabase(
+		context.Background(),
+		Parent{Type: "page_id", PageID: "98ad959b-2b6a-4774-80ee-00246fb0ea9b"},
+		[]RichText{{Type: "text", Text: &Text{Content: "Grocery List"}}},
+		map[string]Property{
+			"Name": {Title: &TitleProperty{}},
+			"Status": {
+				Select: &SelectProperty{
+					Options: []SelectOption{
+						{Name: "To Buy", Color: "red"},
+						{Name: "Bought", Color: "green"},
+					},
+				},
+			},
+		},
+	)
+	if gotErr != nil {
+		t.Fatalf("CreateDatabase() error = %v, wantErr <nil>", gotErr)
+	}
+
+	if capturedRequest.Method != http.MethodPost || capturedRequest.URL.Path != "/v1/databases" {
+		t.Errorf("request = %s %s, want POST /v1/databases", capturedRequest.Method, capturedRequest.URL.Path)
+	}
+	payload, _ := ioutil.ReadAll(capturedRequest.Body)
+	wantPayload := `{"parent":{"type":"page_id","page_id":"98ad959b-2b6a-4774-80ee-00246fb0ea9b"},` +
+		`"title":[{"type":"text","text":{"content":"Grocery List"}}],` +
+		`"properties":{"Name":{"title":{}},"Status":{"select":{"options":[{"name":"To Buy","color":"red"},{"name":"Bought","color":"green"}]}}}}`
+	if string(payload) != wantPayload {
+		t.Errorf("payload = %s, want %s", payload, wantPayload)
+	}
+	wantDB := &Database{Object: "database", ID: "bc1211ca-e3f1-4939-ae34-5260b16f627c"}
+	if diff := cmp.Diff(wantDB, gotDB); diff != "" {
+		t.Errorf("CreateDatabase() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestService_UpdateDatabase(t *testing.T) {
+	tests := []struct {
+		name        string
+		title       []RichText
+		properties  map[string]Property
+		wantPayload string
+	}{
+		{
+			name:        "should rename the database",
+			title:       []RichText{{Type: "text", Text: &Text{Content: "Task List"}}},
+			wantPayload: `{"title":[{"type":"text","text":{"content":"Task List"}}]}`,
+		},
+		{
+			name: "should add a select option",
+			properties: map[string]Property{
+				"Status": {
+					Select: &SelectProperty{
+						Options: []SelectOption{
+							{ID: "1", Name: "To Do", Color: "red"},
+							{Name: "Blocked", Color: "gray"},
+						},
+					},
+				},
+			},
+			wantPayload: `{"properties":{"Status":{"select":{"options":[{"id":"1","name":"To Do","color":"red"},{"name":"Blocked","color":"gray"}]}}}}`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient, capturedRequest := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: 200,
+					Body:       ioutil.NopCloser(bytes.NewBufferString(`{"object":"database","id":"e65ccf14-e13b-48d1-a6d1-b14cd84c4bed"}`)),
+				}, nil
+			})
+			service := New("token", WithHTTPClient(httpClient))
+
+			_, gotErr := service.UpdateDatabase(context.Background(), "e65ccf14-e13b-48d1-a6d1-b14cd84c4bed", tt.title, tt.properties)
+			if gotErr != nil {
+				t.Fatalf("UpdateDatabase() error = %v, wantErr <nil>", gotErr)
+			}
+
+			if capturedRequest.Method != http.MethodPatch {
+				t.Errorf("method = %v, want %v", capturedRequest.Method, http.MethodPatch)
+			}
+			wantPath := "/v1/databases/e65ccf14-e13b-48d1-a6d1-b14cd84c4bed"
+			if gotPath := capturedRequest.URL.Path; gotPath != wantPath {
+				t.Errorf("path = %v, want %v", gotPath, wantPath)
+			}
+			payload, _ := ioutil.ReadAll(capturedRequest.Body)
+			if string(payload) != tt.wantPayload {
+				t.Errorf("payload = %s, want %s", payload, tt.wantPayload)
+			}
+		})
+	}
+}
+
 func TestService_QueryDatabase(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -474,7 +599,7 @@ func TestService_QueryDatabase(t *testing.T) {
 			filter: &Filter{
 				Property: "Foo",
 				Checkbox: &CheckboxFilterCondition{
-					Equals: true,
+					Equals: Bool(true),
 				},
 			},
 			sorts: []Sort{
@@ -503,17 +628,17 @@ func TestService_QueryDatabase(t *testing.T) {
 		},
 		{
 			name:           "should parse an error",
-			databaseID:     "not-uuid",
-			respStatusCode: 400,
+			databaseID:     "a1d8501e-1ac1-43e9-a6bd-ea9fe6c8822b",
+			respStatusCode: 404,
 			respBody: `{
 			  "object": "error",
-			  "status": 400,
-			  "code": "validation_error",
-			  "message": "The provided database ID is not a valid Notion UUID: e65ccf14-e13b-48d1-a6d1-b14cd84c4be."
+			  "status": 404,
+			  "code": "object_not_found",
+			  "message": "Could not find database with ID: a1d8501e-1ac1-43e9-a6bd-ea9fe6c8822b."
 			}`,
-			wantPath:    "/v1/databases/not-uuid/query",
+			wantPath:    "/v1/databases/a1d8501e-1ac1-43e9-a6bd-ea9fe6c8822b/query",
 			wantPayload: "{}",
-			wantErrMsg:  "application error: &{validation_error The provided database ID is not a valid Notion UUID: e65ccf14-e13b-48d1-a6d1-b14cd84c4be.}",
+			wantErrMsg:  "application error: &{object_not_found Could not find database with ID: a1d8501e-1ac1-43e9-a6bd-ea9fe6c8822b.}",
 		},
 	}
 	for _, tt := range tests {
@@ -557,108 +682,1476 @@ func TestService_QueryDatabase(t *testing.T) {
 	}
 }
 
-func TestService_QueryDatabase_Integration(t *testing.T) {
-	token := os.Getenv("NOTION_TOKEN")
-	if token == "" {
-		t.Skip("set NOTION_TOKEN to run this test")
+func TestService_QueryDatabaseAll(t *testing.T) {
+	var gotPayloads []string
+	httpClient, _ := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+		payload, _ := ioutil.ReadAll(req.Body)
+		gotPayloads = append(gotPayloads, string(payload))
+		respBody := `{"object":"list","results":[{"object":"page","id":"page-1"}],"next_cursor":"cursor-2","has_more":true}`
+		if strings.Contains(string(payload), "cursor-2") {
+			respBody = `{"object":"list","results":[{"object":"page","id":"page-2"}],"next_cursor":null,"has_more":false}`
+		}
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(respBody)),
+		}, nil
+	})
+	service := WithCustomHttpClient("token", httpClient, false)
+
+	gotPages, gotErr := service.QueryDatabaseAll(context.Background(), "e65ccf14-e13b-48d1-a6d1-b14cd84c4bed", nil, nil)
+	if gotErr != nil {
+		t.Fatalf("QueryDatabaseAll() error = %v, wantErr <nil>", gotErr)
+	}
+
+	wantPayloads := []string{
+		`{"page_size":100}`,
+		`{"start_cursor":"cursor-2","page_size":100}`,
+	}
+	if diff := cmp.Diff(wantPayloads, gotPayloads); diff != "" {
+		t.Errorf("payloads mismatch (-want +got):\n%s", diff)
 	}
+	wantPages := []Page{
+		{Object: "page", ID: "page-1"},
+		{Object: "page", ID: "page-2"},
+	}
+	if diff := cmp.Diff(wantPages, gotPages); diff != "" {
+		t.Errorf("QueryDatabaseAll() mismatch (-want +got):\n%s", diff)
+	}
+}
 
-	s := New(token, false)
+func TestDatabase_LastEditedAt(t *testing.T) {
+	db := &Database{LastEditedTime: "2021-05-20T09:19:00.000Z"}
 
-	result, err := s.QueryDatabase(
-		context.Background(),
-		"e65ccf14-e13b-48d1-a6d1-b14cd84c4bed",
-		&Filter{Property: "RRGi", Checkbox: &CheckboxFilterCondition{Equals: true}},
-		[]Sort{{Timestamp: "created_time", Direction: SortAsc}},
-		nil,
-	)
+	got, err := db.LastEditedAt()
 	if err != nil {
-		t.Errorf("QueryDatabase error = %v", err)
-		return
+		t.Fatalf("LastEditedAt() error = %v, wantErr <nil>", err)
 	}
-	if len(result.Results) < 2 {
-		t.Errorf("QueryDatabase expected at least two pages, got %v", result.Results)
-		return
+	if want := time.Date(2021, 5, 20, 9, 19, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("LastEditedAt() = %v, want %v", got, want)
 	}
+}
 
-	// Check if it the first two pages have the results that we expect
-	wantTitle1 := "Write more integrations tests"
-	gotTitle1 := result.Results[0].Properties["Name"].Title[0].PlainText
-	if gotTitle1 != wantTitle1 {
-		t.Errorf("Page[0] title = %v, want %v", gotTitle1, wantTitle1)
+func TestService_ListDatabasesAll(t *testing.T) {
+	var gotQueries []string
+	httpClient, _ := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+		gotQueries = append(gotQueries, req.URL.RawQuery)
+		respBody := `{"results":[{"object":"database","id":"db-1"}],"next_cursor":"cursor-2","has_more":true}`
+		if req.URL.Query().Get("start_cursor") == "cursor-2" {
+			respBody = `{"results":[{"object":"database","id":"db-2"}],"next_cursor":null,"has_more":false}`
+		}
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(respBody)),
+		}, nil
+	})
+	service := New("token", WithHTTPClient(httpClient))
+
+	gotDBs, gotErr := service.ListDatabasesAll(context.Background())
+	if gotErr != nil {
+		t.Fatalf("ListDatabasesAll() error = %v, wantErr <nil>", gotErr)
 	}
-	wantTitle2 := "Create an integration test workspace"
-	gotTitle2 := result.Results[1].Properties["Name"].Title[0].PlainText
-	if gotTitle2 != wantTitle2 {
-		t.Errorf("Page[1] title = %v, want %v", gotTitle2, wantTitle2)
+
+	wantQueries := []string{"page_size=100", "page_size=100&start_cursor=cursor-2"}
+	if diff := cmp.Diff(wantQueries, gotQueries); diff != "" {
+		t.Errorf("queries mismatch (-want +got):\n%s", diff)
+	}
+	wantDBs := []Database{
+		{Object: "database", ID: "db-1"},
+		{Object: "database", ID: "db-2"},
+	}
+	if diff := cmp.Diff(wantDBs, gotDBs); diff != "" {
+		t.Errorf("ListDatabasesAll() mismatch (-want +got):\n%s", diff)
 	}
 }
 
-func TestService_ListDatabases_Integration(t *testing.T) {
-	token := os.Getenv("NOTION_TOKEN")
-	if token == "" {
-		t.Skip("set NOTION_TOKEN to run this test")
+var validateTestDatabase = &Database{
+	ID: "e65ccf14-e13b-48d1-a6d1-b14cd84c4bed",
+	Properties: map[string]Property{
+		"Name":      {ID: "title", Type: "title", Title: &TitleProperty{}},
+		"Needs ☕️?": {ID: "RRGi", Type: "checkbox", Checkbox: &CheckboxProperty{}},
+		"Status": {
+			ID:   "^OE@",
+			Type: "select",
+			Select: &SelectProperty{
+				Options: []SelectOption{
+					{ID: "1", Name: "To Do", Color: "red"},
+					{ID: "2", Name: "Doing", Color: "yellow"},
+				},
+			},
+		},
+		"Tag": {
+			ID:   "UHT}",
+			Type: "multi_select",
+			MultiSelect: &MultiSelectProperty{
+				Options: []MultiSelectOption{
+					{ID: "0e8b9aa9-b1c5-4964-812d-207d0aec09cf", Name: "go", Color: "brown"},
+				},
+			},
+		},
+	},
+}
+
+func TestDatabase_NewPageProperties(t *testing.T) {
+	db := *validateTestDatabase
+	db.Properties = map[string]Property{"Created": {ID: "Bd4C", Type: "created_time", CreatedTime: &CreatedTimeProperty{}}}
+	for name, prop := range validateTestDatabase.Properties {
+		db.Properties[name] = prop
 	}
 
-	wantTitle := "Task List 5132beee"
+	got := db.NewPageProperties()
 
-	s := New(token, false)
+	want := map[string]PropertyValue{
+		"Name":      {Type: "title"},
+		"Needs ☕️?": {Type: "checkbox"},
+		"Status":    {Type: "select"},
+		"Tag":       {Type: "multi_select"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("NewPageProperties() mismatch (-want +got):\n%s", diff)
+	}
+}
 
-	// Get the list of the databases, list them one-by-one to exercise the pagination code path
-	var got []Database
+func TestDatabase_PropertyIDByName(t *testing.T) {
+	tests := []struct {
+		name       string
+		property   string
+		wantID     string
+		wantErrMsg string
+	}{
+		{
+			name:     "should resolve a property name to its ID",
+			property: "Needs ☕️?",
+			wantID:   "RRGi",
+		},
+		{
+			name:       "should fail on an unknown property",
+			property:   "Owner",
+			wantErrMsg: "has no property Owner",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			gotID, gotErr := validateTestDatabase.PropertyIDByName(tt.property)
+			if tt.wantErrMsg != "" {
+				if gotErr == nil {
+					gotErr = fmt.Errorf("no error")
+				}
+				if !strings.Contains(gotErr.Error(), tt.wantErrMsg) {
+					t.Errorf("PropertyIDByName() error = %v, wantErr %v", gotErr, tt.wantErrMsg)
+				}
+				return
+			}
+			if gotErr != nil {
+				t.Fatalf("PropertyIDByName() error = %v, wantErr <nil>", gotErr)
+			}
+			if gotID != tt.wantID {
+				t.Errorf("PropertyIDByName() = %v, want %v", gotID, tt.wantID)
+			}
+		})
+	}
+}
 
-	page := Pagination{PageSize: 1}
-	for {
-		result, err := s.ListDatabases(context.Background(), page)
-		if err != nil {
-			t.Errorf("ListDatabases(%v) error = %v", page, err)
-			return
-		}
-		for _, db := range result.Results {
-			got = append(got, db)
-		}
-		if len(result.Results) > page.PageSize {
-			t.Errorf("ListDatabases(%v) got too many items [%s]", page, renderTitles(result.Results))
-			return
-		}
-		if !result.HasMore {
-			break
-		}
-		page.StartCursor = result.NextCursor
+func TestDatabase_HasMoreProperties(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want bool
+	}{
+		{
+			name: "should report a database with a relation property",
+			body: `{
+			  "object": "database",
+			  "id": "e65ccf14-e13b-48d1-a6d1-b14cd84c4bed",
+			  "properties": {
+				"Name": {"id": "title", "type": "title", "title": {}},
+				"Recipes": {"id": "x%3C%3E%5B", "type": "relation", "relation": {"database_id": "3e2df7a9-4a39-4c23-a0b7-b4a5e4a0d5ad"}}
+			  }
+			}`,
+			want: true,
+		},
+		{
+			name: "should report a database with a people property",
+			body: `{
+			  "object": "database",
+			  "id": "e65ccf14-e13b-48d1-a6d1-b14cd84c4bed",
+			  "properties": {
+				"Name": {"id": "title", "type": "title", "title": {}},
+				"Owners": {"id": "Ow%7Dn", "type": "people", "people": {}}
+			  }
+			}`,
+			want: true,
+		},
+		{
+			name: "should not report a database with text and short properties only",
+			body: `{
+			  "object": "database",
+			  "id": "e65ccf14-e13b-48d1-a6d1-b14cd84c4bed",
+			  "properties": {
+				"Name": {"id": "title", "type": "title", "title": {}},
+				"Description": {"id": "J@cS", "type": "rich_text", "rich_text": {}},
+				"In stock": {"id": "%7B%3E%5D", "type": "checkbox", "checkbox": {}}
+			  }
+			}`,
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var db Database
+			if err := json.Unmarshal([]byte(tt.body), &db); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			if got := db.HasMoreProperties(); got != tt.want {
+				t.Errorf("HasMoreProperties() = %v, want %v", got, tt.want)
+			}
+		})
 	}
+}
 
-	// Check if there are at least two databases
-	if len(got) < 2 {
-		t.Errorf("Expected at least 2 databases, got [%s]", renderTitles(got))
-		return
+func TestService_QueryDatabaseAll_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	requests := 0
+	httpClient, _ := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+		requests++
+		// the caller gives up while the first page is on its way
+		cancel()
+		return &http.Response{
+			StatusCode: 200,
+			Body: ioutil.NopCloser(bytes.NewBufferString(
+				`{"object":"list","results":[{"object":"page","id":"page-1"}],"next_cursor":"cursor-2","has_more":true}`,
+			)),
+		}, nil
+	})
+	service := New("token", WithHTTPClient(httpClient))
+
+	gotPages, gotErr := service.QueryDatabaseAll(ctx, "a1d8501e-1ac1-43e9-a6bd-ea9fe6c8822b", nil, nil)
+
+	if !errors.Is(gotErr, context.Canceled) {
+		t.Errorf("QueryDatabaseAll() error = %v, want %v", gotErr, context.Canceled)
+	}
+	if requests != 1 {
+		t.Errorf("requests = %d, want 1", requests)
+	}
+	wantPages := []Page{{Object: "page", ID: "page-1"}}
+	if diff := cmp.Diff(wantPages, gotPages); diff != "" {
+		t.Errorf("QueryDatabaseAll() mismatch (-want +got):\n%s", diff)
 	}
+}
 
-	// Check if it contains the one we know it has to contain
-	var taskList *Database
-FindDB:
-	for _, db := range got {
-		for _, titlet := range db.Title {
-			if strings.Contains(titlet.PlainText, wantTitle) {
-				taskList = &db
-				break FindDB
-			}
-		}
+func TestService_QueryDatabaseAll_MaxPages(t *testing.T) {
+	requests := 0
+	httpClient, _ := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+		requests++
+		respBody := fmt.Sprintf(
+			`{"object":"list","results":[{"object":"page","id":"page-%d"}],"next_cursor":"cursor-%d","has_more":%v}`,
+			requests, requests+1, requests < 5,
+		)
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(respBody)),
+		}, nil
+	})
+	service := New("token", WithHTTPClient(httpClient), WithMaxPages(2))
+
+	gotPages, gotErr := service.QueryDatabaseAll(context.Background(), "e65ccf14-e13b-48d1-a6d1-b14cd84c4bed", nil, nil)
+
+	if !errors.Is(gotErr, ErrMaxPagesExceeded) {
+		t.Errorf("QueryDatabaseAll() error = %v, wantErr %v", gotErr, ErrMaxPagesExceeded)
+	}
+	if requests != 2 {
+		t.Errorf("requests = %d, want 2", requests)
+	}
+	wantPages := []Page{
+		{Object: "page", ID: "page-1"},
+		{Object: "page", ID: "page-2"},
+	}
+	if diff := cmp.Diff(wantPages, gotPages); diff != "" {
+		t.Errorf("QueryDatabaseAll() mismatch (-want +got):\n%s", diff)
 	}
+}
 
-	// If not then lets print what we have to make it easier to debug
-	if taskList == nil {
-		t.Errorf("Test DB [%s] not found. Got: [%s]", wantTitle, renderTitles(got))
+func TestDatabase_ValidateCreate(t *testing.T) {
+	tests := []struct {
+		name       string
+		properties map[string]PropertyValue
+		wantErrMsg string
+	}{
+		{
+			name: "should accept a valid request",
+			properties: map[string]PropertyValue{
+				"Name":   {Type: "title", Title: []RichText{{Type: "text", Text: &Text{Content: "Buy milk"}}}},
+				"Status": {Type: "select", Select: &SelectPropertyValue{Name: "Doing"}},
+				"Tag":    {Type: "multi_select", MultiSelect: []MultiSelectPropertyValue{{Name: "go"}}},
+			},
+		},
+		{
+			name: "should reject an unknown property",
+			properties: map[string]PropertyValue{
+				"Priority": {Type: "select", Select: &SelectPropertyValue{Name: "High"}},
+			},
+			wantErrMsg: `schema validation failed: unknown property "Priority"`,
+		},
+		{
+			name: "should reject a type mismatch",
+			properties: map[string]PropertyValue{
+				"Needs ☕️?": {Type: "rich_text", RichText: []RichText{{Type: "text", Text: &Text{Content: "yes"}}}},
+			},
+			wantErrMsg: `schema validation failed: property "Needs ☕️?" is of type "checkbox", got "rich_text"`,
+		},
+		{
+			name: "should reject unknown select options",
+			properties: map[string]PropertyValue{
+				"Status": {Type: "select", Select: &SelectPropertyValue{Name: "Done"}},
+				"Tag":    {Type: "multi_select", MultiSelect: []MultiSelectPropertyValue{{Name: "go"}, {Name: "rust"}}},
+			},
+			wantErrMsg: `schema validation failed: property "Status" has no option "Done"; property "Tag" has no option "rust"`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			gotErr := validateTestDatabase.ValidateCreate(CreatePageRequest{
+				Parent:     Parent{Type: "database_id", DatabaseID: validateTestDatabase.ID},
+				Properties: tt.properties,
+			})
+			if tt.wantErrMsg != "" {
+				if gotErr == nil {
+					gotErr = fmt.Errorf("no error")
+				}
+				if gotErr.Error() != tt.wantErrMsg {
+					t.Errorf("ValidateCreate() error = %v, wantErr %v", gotErr, tt.wantErrMsg)
+				}
+			} else if gotErr != nil {
+				t.Errorf("ValidateCreate() error = %v, wantErr <nil>", gotErr)
+			}
+		})
 	}
 }
 
-func renderTitles(got []Database) string {
-	allTitles := []string{}
-	for _, db := range got {
-		title := []string{}
-		for _, titlet := range db.Title {
-			title = append(title, titlet.PlainText)
-		}
-		allTitles = append(allTitles, strings.Join(title, ""))
+func TestList_Unmarshal(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		target interface{}
+		want   interface{}
+	}{
+		{
+			name:   "should decode empty page results",
+			body:   `{"object":"list","results":[],"next_cursor":null,"has_more":false}`,
+			target: &PageList{},
+			want:   &PageList{Object: "list", Results: []Page{}},
+		},
+		{
+			name:   "should decode null page results as empty",
+			body:   `{"object":"list","results":null,"next_cursor":null,"has_more":false}`,
+			target: &PageList{},
+			want:   &PageList{Object: "list", Results: []Page{}},
+		},
+		{
+			name:   "should decode empty database results",
+			body:   `{"results":[],"next_cursor":null,"has_more":false}`,
+			target: &DatabaseList{},
+			want:   &DatabaseList{Results: []Database{}},
+		},
+		{
+			name:   "should decode null database results as empty",
+			body:   `{"results":null,"next_cursor":null,"has_more":false}`,
+			target: &DatabaseList{},
+			want:   &DatabaseList{Results: []Database{}},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if err := json.Unmarshal([]byte(tt.body), tt.target); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, tt.target); diff != "" {
+				t.Errorf("json.Unmarshal() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestService_QueryDatabase_StrictDecode(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []Option
+		body       string
+		wantErrMsg string
+	}{
+		{
+			name: "should ignore unknown fields by default",
+			body: `{"object":"list","results":[{"object":"page","bogus":1}],"unknown_top":true}`,
+		},
+		{
+			name:       "should reject an unknown field of the list in the strict mode",
+			opts:       []Option{WithStrictDecode()},
+			body:       `{"object":"list","results":[],"unknown_top":true}`,
+			wantErrMsg: `json: unknown field "unknown_top"`,
+		},
+		{
+			name:       "should reject an unknown field of a page in the strict mode",
+			opts:       []Option{WithStrictDecode()},
+			body:       `{"object":"list","results":[{"object":"page","bogus":1}]}`,
+			wantErrMsg: `json: unknown field "bogus"`,
+		},
+		{
+			name:       "should reject an unknown field of a rich text in the strict mode",
+			opts:       []Option{WithStrictDecode()},
+			body:       `{"object":"list","results":[{"object":"page","properties":{"Name":{"type":"title","title":[{"type":"text","bogus":1}]}}}]}`,
+			wantErrMsg: `json: unknown field "bogus"`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient, _ := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBufferString(tt.body))}, nil
+			})
+			service := New("token", append([]Option{WithHTTPClient(httpClient)}, tt.opts...)...)
+
+			_, gotErr := service.QueryDatabase(
+				context.Background(),
+				"e65ccf14-e13b-48d1-a6d1-b14cd84c4bed",
+				nil,
+				nil,
+				nil,
+			)
+			if tt.wantErrMsg != "" {
+				if gotErr == nil {
+					gotErr = fmt.Errorf("no error")
+				}
+				if !strings.Contains(gotErr.Error(), tt.wantErrMsg) {
+					t.Errorf("QueryDatabase() error = %v, wantErr %v", gotErr, tt.wantErrMsg)
+				}
+				return
+			}
+			if gotErr != nil {
+				t.Errorf("QueryDatabase() error = %v, wantErr <nil>", gotErr)
+			}
+		})
+	}
+}
+
+func TestPageList_EnvelopeType(t *testing.T) {
+	var list PageList
+	if err := json.Unmarshal([]byte(`{"object":"list","type":"page","results":[],"has_more":false}`), &list); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if got := list.EnvelopeType(); got != "page" {
+		t.Errorf("EnvelopeType() = %q, want %q", got, "page")
+	}
+}
+
+func TestService_QueryDatabase_Sorts(t *testing.T) {
+	tests := []struct {
+		name       string
+		sorts      []Sort
+		wantErrMsg string
+	}{
+		{
+			name:  "should accept a valid sort",
+			sorts: []Sort{{Property: "Name", Direction: SortAsc}, {Timestamp: "created_time"}},
+		},
+		{
+			name:       "should reject an invalid direction",
+			sorts:      []Sort{{Property: "Name", Direction: "asc"}},
+			wantErrMsg: `invalid sort direction "asc"`,
+		},
+		{
+			name:       "should reject an empty sort",
+			sorts:      []Sort{{Direction: SortDesc}},
+			wantErrMsg: "sort needs either a property or a timestamp",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			httpClient, _ := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+				requests++
+				return &http.Response{
+					StatusCode: 200,
+					Body:       ioutil.NopCloser(bytes.NewBufferString(`{"object":"list","results":[],"has_more":false}`)),
+				}, nil
+			})
+			service := New("token", WithHTTPClient(httpClient))
+
+			_, gotErr := service.QueryDatabase(context.Background(), "e65ccf14-e13b-48d1-a6d1-b14cd84c4bed", nil, tt.sorts, nil)
+
+			if tt.wantErrMsg == "" {
+				if gotErr != nil {
+					t.Errorf("QueryDatabase() error = %v, wantErr <nil>", gotErr)
+				}
+				return
+			}
+			var clientErr ClientError
+			if !errors.As(gotErr, &clientErr) || !strings.Contains(gotErr.Error(), tt.wantErrMsg) {
+				t.Errorf("QueryDatabase() error = %v, want ClientError %v", gotErr, tt.wantErrMsg)
+			}
+			if requests != 0 {
+				t.Errorf("requests = %d, want 0", requests)
+			}
+		})
+	}
+}
+
+func TestSortBuilder(t *testing.T) {
+	base := SortBuilder{}.ByProperty("Status", SortAsc)
+
+	got := base.ByTimestamp("created_time", SortDesc)
+	other := base.ByProperty("Name", SortAsc)
+
+	want := []Sort{
+		{Property: "Status", Direction: SortAsc},
+		{Timestamp: "created_time", Direction: SortDesc},
+	}
+	if diff := cmp.Diff(want, []Sort(got)); diff != "" {
+		t.Errorf("SortBuilder mismatch (-want +got):\n%s", diff)
+	}
+	if len(base) != 1 || other[1].Property != "Name" {
+		t.Errorf("derived builders share sorts: base = %v, other = %v", base, other)
+	}
+}
+
+func TestFilter_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   string
+	}{
+		{
+			name: "should encode an absolute date condition",
+			filter: &Filter{
+				Property: "Due",
+				Date:     &DateFilterCondition{OnOrAfter: "2021-05-10"},
+			},
+			want: `{"property":"Due","date":{"on_or_after":"2021-05-10"}}`,
+		},
+		{
+			name: "should encode a relative date condition as an empty object",
+			filter: &Filter{
+				Property:    "Date Created",
+				CreatedTime: &DateFilterCondition{PastWeek: true},
+			},
+			want: `{"property":"Date Created","created_time":{"past_week":{}}}`,
+		},
+		{
+			name: "should encode a relation condition",
+			filter: &Filter{
+				Property: "Project",
+				Relation: &RelationFilterCondition{Contains: "a1d8501e-1ac1-43e9-a6bd-ea9fe6c8822b"},
+			},
+			want: `{"property":"Project","relation":{"contains":"a1d8501e-1ac1-43e9-a6bd-ea9fe6c8822b"}}`,
+		},
+		{
+			name: "should encode an empty relation condition",
+			filter: &Filter{
+				Property: "Project",
+				Relation: &RelationFilterCondition{IsEmpty: true},
+			},
+			want: `{"property":"Project","relation":{"is_empty":true}}`,
+		},
+		{
+			name:   "should encode an explicit false checkbox condition",
+			filter: &Filter{Property: "Done", Checkbox: &CheckboxFilterCondition{Equals: Bool(false)}},
+			want:   `{"property":"Done","checkbox":{"equals":false}}`,
+		},
+		{
+			name:   "should encode an explicit zero number condition",
+			filter: &Filter{Property: "Price", Number: &NumberFilterCondition{GreaterThan: Float64(0)}},
+			want:   `{"property":"Price","number":{"greater_than":0}}`,
+		},
+		{
+			name: "should encode a timestamp condition",
+			filter: &Filter{
+				Timestamp:   "created_time",
+				CreatedTime: &DateFilterCondition{After: "2021-05-10"},
+			},
+			want: `{"timestamp":"created_time","created_time":{"after":"2021-05-10"}}`,
+		},
+		{
+			name: "should target a property by ID",
+			filter: func() *Filter {
+				f := FilterByPropertyID("RRGi")
+				f.Checkbox = &CheckboxFilterCondition{Equals: Bool(true)}
+				return f
+			}(),
+			want: `{"property":"RRGi","checkbox":{"equals":true}}`,
+		},
+		{
+			name: "should encode a compound filter",
+			filter: &Filter{
+				CompoundFilter: &CompoundFilter{
+					Or: []Filter{
+						{Property: "Done", Checkbox: &CheckboxFilterCondition{Equals: Bool(true)}},
+						{Property: "Due", Date: &DateFilterCondition{IsEmpty: true}},
+					},
+				},
+			},
+			want: `{"or":[{"property":"Done","checkbox":{"equals":true}},{"property":"Due","date":{"is_empty":true}}]}`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.filter)
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("json.Marshal() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDueToday(t *testing.T) {
+	warsaw, err := time.LoadLocation("Europe/Warsaw")
+	if err != nil {
+		t.Skipf("can't load the time zone: %v", err)
+	}
+	tests := []struct {
+		name string
+		now  time.Time
+		want string
+	}{
+		{
+			name: "should span the local day",
+			now:  time.Date(2021, 5, 20, 23, 30, 0, 0, warsaw),
+			want: `{"and":[` +
+				`{"property":"Due","date":{"on_or_after":"2021-05-20T00:00:00+02:00"}},` +
+				`{"property":"Due","date":{"before":"2021-05-21T00:00:00+02:00"}}]}`,
+		},
+		{
+			name: "should follow the offset change on the daylight saving time switch",
+			now:  time.Date(2021, 3, 28, 12, 0, 0, 0, warsaw),
+			want: `{"and":[` +
+				`{"property":"Due","date":{"on_or_after":"2021-03-28T00:00:00+01:00"}},` +
+				`{"property":"Due","date":{"before":"2021-03-29T00:00:00+02:00"}}]}`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(&Filter{CompoundFilter: dueToday("Due", tt.now)})
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("json.Marshal() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_QueryDatabase_Integration(t *testing.T) {
+	token := os.Getenv("NOTION_TOKEN")
+	if token == "" {
+		t.Skip("set NOTION_TOKEN to run this test")
+	}
+
+	s := New(token)
+
+	result, err := s.QueryDatabase(
+		context.Background(),
+		"e65ccf14-e13b-48d1-a6d1-b14cd84c4bed",
+		&Filter{Property: "RRGi", Checkbox: &CheckboxFilterCondition{Equals: Bool(true)}},
+		[]Sort{{Timestamp: "created_time", Direction: SortAsc}},
+		nil,
+	)
+	if err != nil {
+		t.Errorf("QueryDatabase error = %v", err)
+		return
+	}
+	if len(result.Results) < 2 {
+		t.Errorf("QueryDatabase expected at least two pages, got %v", result.Results)
+		return
+	}
+
+	// Check if it the first two pages have the results that we expect
+	wantTitle1 := "Write more integrations tests"
+	gotTitle1 := result.Results[0].Properties["Name"].PlainText()
+	if gotTitle1 != wantTitle1 {
+		t.Errorf("Page[0] title = %v, want %v", gotTitle1, wantTitle1)
+	}
+	wantTitle2 := "Create an integration test workspace"
+	gotTitle2 := result.Results[1].Properties["Name"].PlainText()
+	if gotTitle2 != wantTitle2 {
+		t.Errorf("Page[1] title = %v, want %v", gotTitle2, wantTitle2)
+	}
+}
+
+func TestService_ListDatabases_Integration(t *testing.T) {
+	token := os.Getenv("NOTION_TOKEN")
+	if token == "" {
+		t.Skip("set NOTION_TOKEN to run this test")
+	}
+
+	wantTitle := "Task List 5132beee"
+
+	s := New(token)
+
+	// Get the list of the databases, list them one-by-one to exercise the pagination code path
+	var got []Database
+
+	page := Pagination{PageSize: 1}
+	for {
+		result, err := s.ListDatabases(context.Background(), page)
+		if err != nil {
+			t.Errorf("ListDatabases(%v) error = %v", page, err)
+			return
+		}
+		for _, db := range result.Results {
+			got = append(got, db)
+		}
+		if len(result.Results) > page.PageSize {
+			t.Errorf("ListDatabases(%v) got too many items [%s]", page, renderTitles(result.Results))
+			return
+		}
+		if !result.HasMore {
+			break
+		}
+		page.StartCursor = result.NextCursor
+	}
+
+	// Check if there are at least two databases
+	if len(got) < 2 {
+		t.Errorf("Expected at least 2 databases, got [%s]", renderTitles(got))
+		return
+	}
+
+	// Check if it contains the one we know it has to contain
+	var taskList *Database
+FindDB:
+	for _, db := range got {
+		for _, titlet := range db.Title {
+			if strings.Contains(titlet.PlainText, wantTitle) {
+				taskList = &db
+				break FindDB
+			}
+		}
+	}
+
+	// If not then lets print what we have to make it easier to debug
+	if taskList == nil {
+		t.Errorf("Test DB [%s] not found. Got: [%s]", wantTitle, renderTitles(got))
+	}
+}
+
+func renderTitles(got []Database) string {
+	allTitles := []string{}
+	for _, db := range got {
+		title := []string{}
+		for _, titlet := range db.Title {
+			title = append(title, titlet.PlainText)
+		}
+		allTitles = append(allTitles, strings.Join(title, ""))
+	}
+	return strings.Join(allTitles, ", ")
+}
+
+func TestPaginated(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		list       Paginated
+		wantMore   bool
+		wantCursor string
+	}{
+		{
+			name:       "should decode a page list with more results",
+			body:       `{"object":"list","results":[],"next_cursor":"fe2cc560-036c-44cd-90e8-294d5a74cebc","has_more":true}`,
+			list:       &PageList{},
+			wantMore:   true,
+			wantCursor: "fe2cc560-036c-44cd-90e8-294d5a74cebc",
+		},
+		{
+			name: "should decode the last page of a database list",
+			body: `{"object":"list","results":[],"next_cursor":null,"has_more":false}`,
+			list: &DatabaseList{},
+		},
+		{
+			name:       "should decode a database list with more results",
+			body:       `{"object":"list","results":[],"next_cursor":"a1d8501e-1ac1-43e9-a6bd-ea9fe6c8822b","has_more":true}`,
+			list:       &DatabaseList{},
+			wantMore:   true,
+			wantCursor: "a1d8501e-1ac1-43e9-a6bd-ea9fe6c8822b",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if err := json.Unmarshal([]byte(tt.body), tt.list); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			if got := tt.list.More(); got != tt.wantMore {
+				t.Errorf("More() = %v, want %v", got, tt.wantMore)
+			}
+			if got := tt.list.Cursor(); got != tt.wantCursor {
+				t.Errorf("Cursor() = %q, want %q", got, tt.wantCursor)
+			}
+		})
+	}
+}
+
+func TestService_QueryDatabase_PageSize(t *testing.T) {
+	tests := []struct {
+		name        string
+		page        *Pagination
+		wantPayload string
+		wantErrMsg  string
+	}{
+		{
+			name:        "should not send an unset page size",
+			page:        &Pagination{},
+			wantPayload: `{}`,
+		},
+		{
+			name:        "should send a page size of 1",
+			page:        &Pagination{PageSize: 1},
+			wantPayload: `{"page_size":1}`,
+		},
+		{
+			name:        "should send a page size of 100",
+			page:        &Pagination{PageSize: 100},
+			wantPayload: `{"page_size":100}`,
+		},
+		{
+			name:       "should reject a page size over 100",
+			page:       &Pagination{PageSize: 101},
+			wantErrMsg: "local error: invalid page size 101, want at most 100",
+		},
+		{
+			name:       "should reject a negative page size",
+			page:       &Pagination{PageSize: -1},
+			wantErrMsg: "local error: invalid page size -1, want at most 100",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient, capturedRequest := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: 200,
+					Body:       ioutil.NopCloser(bytes.NewBufferString(`{"object":"list","results":[],"has_more":false}`)),
+				}, nil
+			})
+			service := New("token", WithHTTPClient(httpClient))
+
+			_, gotErr := service.QueryDatabase(context.Background(), "a1d8501e-1ac1-43e9-a6bd-ea9fe6c8822b", nil, nil, tt.page)
+			if tt.wantErrMsg != "" {
+				if gotErr == nil {
+					gotErr = fmt.Errorf("no error")
+				}
+				if !strings.Contains(gotErr.Error(), tt.wantErrMsg) {
+					t.Errorf("QueryDatabase() error = %v, wantErr %v", gotErr, tt.wantErrMsg)
+				}
+				if capturedRequest.URL != nil {
+					t.Errorf("QueryDatabase() sent a request, want none")
+				}
+				return
+			}
+			if gotErr != nil {
+				t.Fatalf("QueryDatabase() error = %v, wantErr <nil>", gotErr)
+			}
+
+			payload, _ := ioutil.ReadAll(capturedRequest.Body)
+			if string(payload) != tt.wantPayload {
+				t.Errorf("payload = %s, want %s", payload, tt.wantPayload)
+			}
+		})
+	}
+}
+
+func TestService_ListDatabases_PageSize(t *testing.T) {
+	tests := []struct {
+		name       string
+		page       Pagination
+		wantErrMsg string
+	}{
+		{
+			name: "should accept the default page size",
+			page: Pagination{},
+		},
+		{
+			name: "should accept a page size of 100",
+			page: Pagination{PageSize: 100},
+		},
+		{
+			name:       "should reject a page size over 100",
+			page:       Pagination{PageSize: 101},
+			wantErrMsg: "local error: invalid page size 101, want at most 100",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient, _ := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: 200,
+					Body:       ioutil.NopCloser(bytes.NewBufferString(`{"object":"list","results":[],"has_more":false}`)),
+				}, nil
+			})
+			service := New("token", WithHTTPClient(httpClient))
+
+			_, gotErr := service.ListDatabases(context.Background(), tt.page)
+			if tt.wantErrMsg != "" {
+				if gotErr == nil {
+					gotErr = fmt.Errorf("no error")
+				}
+				if !strings.Contains(gotErr.Error(), tt.wantErrMsg) {
+					t.Errorf("ListDatabases() error = %v, wantErr %v", gotErr, tt.wantErrMsg)
+				}
+			} else if gotErr != nil {
+				t.Errorf("ListDatabases() error = %v, wantErr <nil>", gotErr)
+			}
+		})
+	}
+}
+
+func TestService_EnsureSelectOption(t *testing.T) {
+	tests := []struct {
+		name         string
+		optionName   string
+		wantRequests []string
+		wantPayload  string
+	}{
+		{
+			name:         "should add a missing option",
+			optionName:   "Done",
+			wantRequests: []string{"GET", "PATCH"},
+			wantPayload:  `{"properties":{"Status":{"select":{"options":[{"id":"1","name":"To Do","color":"red"},{"name":"Done","color":"green"}]}}}}`,
+		},
+		{
+			name:         "should not update an existing option",
+			optionName:   "To Do",
+			wantRequests: []string{"GET"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var gotRequests []string
+			var gotPayload []byte
+			httpClient, _ := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+				gotRequests = append(gotRequests, req.Method)
+				if req.Method == http.MethodPatch {
+					gotPayload, _ = ioutil.ReadAll(req.Body)
+				}
+				return &http.Response{
+					StatusCode: 200,
+					Body: ioutil.NopCloser(bytes.NewBufferString(`{
+					  "object": "database",
+					  "id": "e65ccf14-e13b-48d1-a6d1-b14cd84c4bed",
+					  "properties": {
+						"Status": {
+						  "id": "^OE@",
+						  "type": "select",
+						  "select": {"options": [{"id": "1", "name": "To Do", "color": "red"}]}
+						}
+					  }
+					}`)),
+				}, nil
+			})
+			service := New("token", WithHTTPClient(httpClient))
+
+			gotErr := service.EnsureSelectOption(
+				context.Background(),
+				"e65ccf14-e13b-48d1-a6d1-b14cd84c4bed",
+				"Status",
+				tt.optionName,
+				ColorGreen,
+			)
+			if gotErr != nil {
+				t.Fatalf("EnsureSelectOption() error = %v, wantErr <nil>", gotErr)
+			}
+
+			if diff := cmp.Diff(tt.wantRequests, gotRequests); diff != "" {
+				t.Errorf("requests mismatch (-want +got):\n%s", diff)
+			}
+			if string(gotPayload) != tt.wantPayload {
+				t.Errorf("payload = %s, want %s", gotPayload, tt.wantPayload)
+			}
+		})
+	}
+}
+
+func TestService_QueryDatabase_InvalidFilter(t *testing.T) {
+	tests := []struct {
+		name       string
+		filter     *Filter
+		wantErrMsg string
+	}{
+		{
+			name:   "should accept a single condition",
+			filter: &Filter{Property: "Done", Checkbox: &CheckboxFilterCondition{Equals: Bool(true)}},
+		},
+		{
+			name: "should reject a filter with two conditions",
+			filter: &Filter{
+				Property: "Done",
+				Checkbox: &CheckboxFilterCondition{Equals: Bool(true)},
+				Number:   &NumberFilterCondition{IsEmpty: true},
+			},
+			wantErrMsg: "local error: invalid filter: property Done needs exactly one condition, got 2",
+		},
+		{
+			name: "should reject a nested filter with two conditions",
+			filter: &Filter{CompoundFilter: &CompoundFilter{Or: []Filter{
+				{Property: "Done", Checkbox: &CheckboxFilterCondition{Equals: Bool(true)}},
+				{Property: "Due", Date: &DateFilterCondition{PastWeek: true}, Status: &StatusFilterCondition{IsEmpty: true}},
+			}}},
+			wantErrMsg: "local error: invalid filter: property Due needs exactly one condition, got 2",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient, capturedRequest := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: 200,
+					Body:       ioutil.NopCloser(bytes.NewBufferString(`{"object":"list","results":[],"has_more":false}`)),
+				}, nil
+			})
+			service := New("token", WithHTTPClient(httpClient))
+
+			_, gotErr := service.QueryDatabase(context.Background(), "a1d8501e-1ac1-43e9-a6bd-ea9fe6c8822b", tt.filter, nil, nil)
+
+			if tt.wantErrMsg != "" {
+				if gotErr == nil {
+					gotErr = fmt.Errorf("no error")
+				}
+				if !strings.Contains(gotErr.Error(), tt.wantErrMsg) {
+					t.Errorf("QueryDatabase() error = %v, wantErr %v", gotErr, tt.wantErrMsg)
+				}
+				if capturedRequest.URL != nil {
+					t.Errorf("QueryDatabase() sent a request, want none")
+				}
+				return
+			}
+			if gotErr != nil {
+				t.Errorf("QueryDatabase() error = %v, wantErr <nil>", gotErr)
+			}
+		})
+	}
+}
+
+func TestService_QueryDatabase_TitleFilter(t *testing.T) {
+	tests := []struct {
+		name        string
+		filter      *Filter
+		wantPayload string
+	}{
+		{
+			name:        "should filter by the title property ID",
+			filter:      TitleFilter("Buy milk"),
+			wantPayload: `{"filter":{"property":"title","title":{"equals":"Buy milk"}}}`,
+		},
+		{
+			name:        "should filter by the title property name",
+			filter:      &Filter{Property: "Name", Title: &TextFilterCondition{StartsWith: "Buy"}},
+			wantPayload: `{"filter":{"property":"Name","title":{"starts_with":"Buy"}}}`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient, capturedRequest := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: 200,
+					Body:       ioutil.NopCloser(bytes.NewBufferString(`{"object":"list","results":[],"has_more":false}`)),
+				}, nil
+			})
+			service := New("token", WithHTTPClient(httpClient))
+
+			if _, err := service.QueryDatabase(context.Background(), "a1d8501e-1ac1-43e9-a6bd-ea9fe6c8822b", tt.filter, nil, nil); err != nil {
+				t.Fatalf("QueryDatabase() error = %v, wantErr <nil>", err)
+			}
+
+			payload, _ := ioutil.ReadAll(capturedRequest.Body)
+			if string(payload) != tt.wantPayload {
+				t.Errorf("payload = %s, want %s", payload, tt.wantPayload)
+			}
+		})
+	}
+}
+
+func TestDatabase_UnmarshalNumberFormat(t *testing.T) {
+	body := `{
+	  "object": "database",
+	  "id": "e65ccf14-e13b-48d1-a6d1-b14cd84c4bed",
+	  "properties": {
+		"Price": {"id": "Ac%3Bp", "type": "number", "number": {"format": "dollar"}}
+	  }
+	}`
+
+	var got Database
+	if err := json.Unmarshal([]byte(body), &got); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	want := Property{ID: "Ac%3Bp", Type: "number", Number: &NumberProperty{Format: "dollar"}}
+	if diff := cmp.Diff(want, got.Properties["Price"]); diff != "" {
+		t.Errorf("json.Unmarshal() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDatabase_UnmarshalProperties(t *testing.T) {
+	body := `{
+	  "object": "database",
+	  "id": "e65ccf14-e13b-48d1-a6d1-b14cd84c4bed",
+	  "properties": {
+		"Link": {"id": "BZKU", "type": "url", "url": {}},
+		"Contact": {"id": "y@Yh", "type": "email", "email": {}},
+		"Phone": {"id": "_A<p", "type": "phone_number", "phone_number": {}},
+		"Due": {"id": "M;Bw", "type": "date", "date": {}},
+		"Owner": {"id": "FlgQ", "type": "people", "people": {}},
+		"Photo": {"id": "CPcK", "type": "files", "files": {}},
+		"Projects": {
+		  "id": "hjW}",
+		  "type": "relation",
+		  "relation": {
+			"database_id": "a1d8501e-1ac1-43e9-a6bd-ea9fe6c8822b",
+			"synced_property_name": "Tasks",
+			"synced_property_id": "wX%5Bc"
+		  }
+		},
+		"Cost": {
+		  "id": "tqyU",
+		  "type": "rollup",
+		  "rollup": {
+			"relation_property_name": "Projects",
+			"relation_property_id": "hjW}",
+			"rollup_property_name": "Budget",
+			"rollup_property_id": "Ac%3Bp",
+			"function": "sum"
+		  }
+		},
+		"Days left": {"id": "%3FpmK", "type": "formula", "formula": {"expression": "dateBetween(prop(\"Due\"), now(), \"days\")"}}
+	  }
+	}`
+
+	var got Database
+	if err := json.Unmarshal([]byte(body), &got); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	want := map[string]Property{
+		"Link":    {ID: "BZKU", Type: "url", URL: &URLProperty{}},
+		"Contact": {ID: "y@Yh", Type: "email", Email: &EmailProperty{}},
+		"Phone":   {ID: "_A<p", Type: "phone_number", PhoneNumber: &PhoneNumberProperty{}},
+		"Due":     {ID: "M;Bw", Type: "date", Date: &DateProperty{}},
+		"Owner":   {ID: "FlgQ", Type: "people", People: &PeopleProperty{}},
+		"Photo":   {ID: "CPcK", Type: "files", Files: &FilesProperty{}},
+		"Projects": {
+			ID:   "hjW}",
+			Type: "relation",
+			Relation: &RelationProperty{
+				DatabaseID:         "a1d8501e-1ac1-43e9-a6bd-ea9fe6c8822b",
+				SyncedPropertyName: "Tasks",
+				SyncedPropertyID:   "wX%5Bc",
+			},
+		},
+		"Cost": {
+			ID:   "tqyU",
+			Type: "rollup",
+			Rollup: &RollupProperty{
+				RelationPropertyName: "Projects",
+				RelationPropertyID:   "hjW}",
+				RollupPropertyName:   "Budget",
+				RollupPropertyID:     "Ac%3Bp",
+				Function:             "sum",
+			},
+		},
+		"Days left": {
+			ID:      "%3FpmK",
+			Type:    "formula",
+			Formula: &FormulaProperty{Expression: `dateBetween(prop("Due"), now(), "days")`},
+		},
+	}
+	if diff := cmp.Diff(want, got.Properties); diff != "" {
+		t.Errorf("json.Unmarshal() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestService_ListDatabasesViaSearch(t *testing.T) {
+	httpClient, capturedRequest := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 200,
+			Body: ioutil.NopCloser(bytes.NewBufferString(`{
+			  "object": "list",
+			  "results": [
+				{
+				  "object": "database",
+				  "id": "e65ccf14-e13b-48d1-a6d1-b14cd84c4bed",
+				  "title": [{"type": "text", "text": {"content": "Grocery List"}, "plain_text": "Grocery List"}]
+				}
+			  ],
+			  "next_cursor": "a1d8501e-1ac1-43e9-a6bd-ea9fe6c8822b",
+			  "has_more": true
+			}`)),
+		}, nil
+	})
+	service := New("token", WithHTTPClient(httpClient))
+
+	gotDBs, gotErr := service.ListDatabasesViaSearch(context.Background(), Pagination{StartCursor: "cursor-1", PageSize: 10})
+	if gotErr != nil {
+		t.Fatalf("ListDatabasesViaSearch() error = %v, wantErr <nil>", gotErr)
+	}
+
+	if capturedRequest.Method != http.MethodPost || capturedRequest.URL.Path != "/v1/search" {
+		t.Errorf("request = %s %s, want POST /v1/search", capturedRequest.Method, capturedRequest.URL.Path)
+	}
+	payload, _ := ioutil.ReadAll(capturedRequest.Body)
+	wantPayload := `{"filter":{"property":"object","value":"database"},"start_cursor":"cursor-1","page_size":10}`
+	if string(payload) != wantPayload {
+		t.Errorf("payload = %s, want %s", payload, wantPayload)
+	}
+	wantDBs := &DatabaseList{
+		HasMore:    true,
+		NextCursor: "a1d8501e-1ac1-43e9-a6bd-ea9fe6c8822b",
+		Results: []Database{
+			{
+				Object: "database",
+				ID:     "e65ccf14-e13b-48d1-a6d1-b14cd84c4bed",
+				Title:  []RichText{{Type: "text", Text: &Text{Content: "Grocery List"}, PlainText: "Grocery List"}},
+			},
+		},
+	}
+	if diff := cmp.Diff(wantDBs, gotDBs); diff != "" {
+		t.Errorf("ListDatabasesViaSearch() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestService_QueryDatabaseRaw(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantErrMsg string
+	}{
+		{
+			name: "should send the body unchanged",
+			body: `{
+			  "filter": {"property": "Tags", "multi_select": {"contains": "go"}},
+			  "sorts": [{"property": "Name", "direction": "ascending"}]
+			}`,
+		},
+		{
+			name:       "should reject a body which isn't JSON",
+			body:       `{"filter":`,
+			wantErrMsg: "local error: invalid query body, want JSON",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient, capturedRequest := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: 200,
+					Body: ioutil.NopCloser(bytes.NewBufferString(
+						`{"object":"list","results":[{"object":"page","id":"251d2b5f-268c-4de2-afe9-c71ff92ca95c"}],"has_more":false}`,
+					)),
+				}, nil
+			})
+			service := New("token", WithHTTPClient(httpClient))
+
+			gotPages, gotErr := service.QueryDatabaseRaw(
+				context.Background(),
+				"a1d8501e-1ac1-43e9-a6bd-ea9fe6c8822b",
+				json.RawMessage(tt.body),
+			)
+
+			if tt.wantErrMsg != "" {
+				if gotErr == nil {
+					gotErr = fmt.Errorf("no error")
+				}
+				if !strings.Contains(gotErr.Error(), tt.wantErrMsg) {
+					t.Errorf("QueryDatabaseRaw() error = %v, wantErr %v", gotErr, tt.wantErrMsg)
+				}
+				return
+			}
+			if gotErr != nil {
+				t.Fatalf("QueryDatabaseRaw() error = %v, wantErr <nil>", gotErr)
+			}
+			if capturedRequest.URL.Path != "/v1/databases/a1d8501e-1ac1-43e9-a6bd-ea9fe6c8822b/query" {
+				t.Errorf("path = %v, want the query endpoint", capturedRequest.URL.Path)
+			}
+			payload, _ := ioutil.ReadAll(capturedRequest.Body)
+			if string(payload) != tt.body {
+				t.Errorf("payload = %s, want %s", payload, tt.body)
+			}
+			wantPages := &PageList{
+				Object:  "list",
+				Results: []Page{{Object: "page", ID: "251d2b5f-268c-4de2-afe9-c71ff92ca95c"}},
+			}
+			if diff := cmp.Diff(wantPages, gotPages); diff != "" {
+				t.Errorf("QueryDatabaseRaw() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestService_RetrieveDatabase_ETag(t *testing.T) {
+	var gotConditions []string
+	httpClient, _ := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+		gotConditions = append(gotConditions, req.Header.Get("If-None-Match"))
+		if req.Header.Get("If-None-Match") == `"v1"` {
+			return &http.Response{
+				StatusCode: 304,
+				Header:     http.Header{"Etag": []string{`"v1"`}},
+				Body:       ioutil.NopCloser(bytes.NewBufferString("")),
+			}, nil
+		}
+		return &http.Response{
+			StatusCode: 200,
+			Header:     http.Header{"Etag": []string{`"v1"`}},
+			Body: ioutil.NopCloser(bytes.NewBufferString(`{
+			  "object": "database",
+			  "id": "e65ccf14-e13b-48d1-a6d1-b14cd84c4bed",
+			  "properties": {"Name": {"id": "title", "type": "title", "title": {}}}
+			}`)),
+		}, nil
+	})
+	service := New("token", WithHTTPClient(httpClient))
+
+	first, err := service.RetrieveDatabase(context.Background(), "e65ccf14-e13b-48d1-a6d1-b14cd84c4bed")
+	if err != nil {
+		t.Fatalf("RetrieveDatabase() error = %v, wantErr <nil>", err)
+	}
+	second, err := service.RetrieveDatabase(context.Background(), "e65ccf14-e13b-48d1-a6d1-b14cd84c4bed")
+	if err != nil {
+		t.Fatalf("RetrieveDatabase() error = %v on a 304, wantErr <nil>", err)
+	}
+
+	if diff := cmp.Diff([]string{"", `"v1"`}, gotConditions); diff != "" {
+		t.Errorf("If-None-Match mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(first, second); diff != "" {
+		t.Errorf("RetrieveDatabase() on a 304 mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestService_With_SharesDatabaseCache(t *testing.T) {
+	var gotConditions []string
+	httpClient, _ := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+		gotConditions = append(gotConditions, req.Header.Get("If-None-Match"))
+		if req.Header.Get("If-None-Match") == `"v1"` {
+			return &http.Response{
+				StatusCode: 304,
+				Header:     http.Header{"Etag": []string{`"v1"`}},
+				Body:       ioutil.NopCloser(bytes.NewBufferString("")),
+			}, nil
+		}
+		return &http.Response{
+			StatusCode: 200,
+			Header:     http.Header{"Etag": []string{`"v1"`}},
+			Body: ioutil.NopCloser(bytes.NewBufferString(`{
+			  "object": "database",
+			  "id": "e65ccf14-e13b-48d1-a6d1-b14cd84c4bed",
+			  "properties": {"Name": {"id": "title", "type": "title", "title": {}}}
+			}`)),
+		}, nil
+	})
+	service := New("token", WithHTTPClient(httpClient))
+
+	want, err := service.RetrieveDatabase(context.Background(), "e65ccf14-e13b-48d1-a6d1-b14cd84c4bed")
+	if err != nil {
+		t.Fatalf("RetrieveDatabase() error = %v, wantErr <nil>", err)
+	}
+	got, err := service.With(WithTimeout(time.Second)).
+		RetrieveDatabase(context.Background(), "e65ccf14-e13b-48d1-a6d1-b14cd84c4bed")
+	if err != nil {
+		t.Fatalf("With().RetrieveDatabase() error = %v, wantErr <nil>", err)
+	}
+
+	if diff := cmp.Diff([]string{"", `"v1"`}, gotConditions); diff != "" {
+		t.Errorf("If-None-Match mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("With().RetrieveDatabase() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestService_RetrieveDatabase_ETagCopy(t *testing.T) {
+	httpClient, _ := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+		if req.Header.Get("If-None-Match") == `"v1"` {
+			return &http.Response{
+				StatusCode: 304,
+				Header:     http.Header{"Etag": []string{`"v1"`}},
+				Body:       ioutil.NopCloser(bytes.NewBufferString("")),
+			}, nil
+		}
+		return &http.Response{
+			StatusCode: 200,
+			Header:     http.Header{"Etag": []string{`"v1"`}},
+			Body: ioutil.NopCloser(bytes.NewBufferString(`{
+			  "object": "database",
+			  "id": "e65ccf14-e13b-48d1-a6d1-b14cd84c4bed",
+			  "title": [{"type": "text", "text": {"content": "Grocery List"}, "plain_text": "Grocery List"}],
+			  "properties": {"Name": {"id": "title", "type": "title", "title": {}}}
+			}`)),
+		}, nil
+	})
+	service := New("token", WithHTTPClient(httpClient))
+	want := &Database{
+		Object:     "database",
+		ID:         "e65ccf14-e13b-48d1-a6d1-b14cd84c4bed",
+		Title:      []RichText{{Type: "text", Text: &Text{Content: "Grocery List"}, PlainText: "Grocery List"}},
+		Properties: map[string]Property{"Name": {ID: "title", Type: "title", Title: &TitleProperty{}}},
+	}
+
+	for i := 0; i < 3; i++ {
+		got, err := service.RetrieveDatabase(context.Background(), "e65ccf14-e13b-48d1-a6d1-b14cd84c4bed")
+		if err != nil {
+			t.Fatalf("RetrieveDatabase() #%d error = %v, wantErr <nil>", i, err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("RetrieveDatabase() #%d mismatch (-want +got):\n%s", i, diff)
+		}
+		// the changes of the caller don't leak into the cached database
+		got.Properties["Price"] = Property{ID: "price", Type: "number"}
+		got.Title[0] = NewText("Shopping List")
+	}
+}
+
+func TestService_QueryDatabaseBySelect(t *testing.T) {
+	httpClient, capturedRequest := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 200,
+			Body: ioutil.NopCloser(bytes.NewBufferString(
+				`{"object":"list","results":[{"object":"page","id":"251d2b5f-268c-4de2-afe9-c71ff92ca95c"}],"has_more":false}`,
+			)),
+		}, nil
+	})
+	service := New("token", WithHTTPClient(httpClient))
+
+	gotPages, gotErr := service.QueryDatabaseBySelect(
+		context.Background(),
+		"a1d8501e1ac143e9a6bdea9fe6c8822b",
+		"Status",
+		"Done",
+		SortBuilder{}.ByTimestamp("last_edited_time", SortDesc),
+	)
+	if gotErr != nil {
+		t.Fatalf("QueryDatabaseBySelect() error = %v, wantErr <nil>", gotErr)
+	}
+
+	wantPath := "/v1/databases/a1d8501e-1ac1-43e9-a6bd-ea9fe6c8822b/query"
+	if capturedRequest.Method != http.MethodPost || capturedRequest.URL.Path != wantPath {
+		t.Errorf("request = %s %s, want POST %s", capturedRequest.Method, capturedRequest.URL.Path, wantPath)
+	}
+	payload, _ := ioutil.ReadAll(capturedRequest.Body)
+	wantPayload := `{"filter":{"property":"Status","select":{"equals":"Done"}},` +
+		`"sorts":[{"timestamp":"last_edited_time","direction":"descending"}]}`
+	if string(payload) != wantPayload {
+		t.Errorf("payload = %s, want %s", payload, wantPayload)
+	}
+	if len(gotPages.Results) != 1 {
+		t.Errorf("QueryDatabaseBySelect() = %+v, want one page", gotPages)
 	}
-	return strings.Join(allTitles, ", ")
 }
@@ -0,0 +1,66 @@
+package notion
+
+import (
+	"errors"
+
+	"notion-go/client"
+)
+
+// Error codes returned by the API
+//
+// See https://developers.notion.com/reference/errors
+const (
+	ErrorCodeInvalidJSON         = "invalid_json"
+	ErrorCodeInvalidRequestURL   = "invalid_request_url"
+	ErrorCodeInvalidRequest      = "invalid_request"
+	ErrorCodeValidation          = "validation_error"
+	ErrorCodeMissingVersion      = "missing_version"
+	ErrorCodeUnauthorized        = "unauthorized"
+	ErrorCodeRestrictedResource  = "restricted_resource"
+	ErrorCodeObjectNotFound      = "object_not_found"
+	ErrorCodeConflict            = "conflict_error"
+	ErrorCodeRateLimited         = "rate_limited"
+	ErrorCodeInternalServerError = "internal_server_error"
+	ErrorCodeServiceUnavailable  = "service_unavailable"
+)
+
+// ErrMaxPagesExceeded is returned by the auto-paginating helpers when they hit the limit set with WithMaxPages
+var ErrMaxPagesExceeded = errors.New("notion: maximum number of result pages exceeded")
+
+// Error represents an error returned by the API
+//
+// See https://developers.notion.com/reference/errors
+type Error struct {
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// ErrorCode returns the code of the error, it makes the client tell the conflicts apart
+func (e *Error) ErrorCode() string {
+	return e.Code
+}
+
+// IsNotFound tells if err was caused by the API responding with the object_not_found error
+func IsNotFound(err error) bool {
+	return hasErrorCode(err, ErrorCodeObjectNotFound)
+}
+
+// IsUnauthorized tells if err was caused by the API responding with the unauthorized error
+func IsUnauthorized(err error) bool {
+	return hasErrorCode(err, ErrorCodeUnauthorized)
+}
+
+func hasErrorCode(err error, code string) bool {
+	apiErr, ok := asError(err)
+	return ok && apiErr.Code == code
+}
+
+// asError unwraps err to the Error returned by the API
+func asError(err error) (*Error, bool) {
+	var appErr client.ApplicationError
+	if !errors.As(err, &appErr) {
+		return nil, false
+	}
+	apiErr, ok := appErr.Failure().(*Error)
+	return apiErr, ok
+}
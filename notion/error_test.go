@@ -0,0 +1,75 @@
+package notion
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestIsNotFound_IsUnauthorized(t *testing.T) {
+	tests := []struct {
+		name             string
+		respStatusCode   int
+		respBody         string
+		wantNotFound     bool
+		wantUnauthorized bool
+	}{
+		{
+			name:           "should detect object not found",
+			respStatusCode: 404,
+			respBody: `{
+			  "object": "error",
+			  "status": 404,
+			  "code": "object_not_found",
+			  "message": "Could not find database with ID: e65ccf14-e13b-48d1-a6d1-b14cd84c4bed."
+			}`,
+			wantNotFound: true,
+		},
+		{
+			name:           "should detect unauthorized",
+			respStatusCode: 401,
+			respBody: `{
+			  "object": "error",
+			  "status": 401,
+			  "code": "unauthorized",
+			  "message": "API token is invalid."
+			}`,
+			wantUnauthorized: true,
+		},
+		{
+			name:           "should ignore other errors",
+			respStatusCode: 400,
+			respBody: `{
+			  "object": "error",
+			  "status": 400,
+			  "code": "validation_error",
+			  "message": "The provided database ID is not a valid Notion UUID: not-uuid."
+			}`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient, _ := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: tt.respStatusCode,
+					Body:       ioutil.NopCloser(bytes.NewBufferString(tt.respBody)),
+				}, nil
+			})
+			service := New("token", WithHTTPClient(httpClient))
+
+			_, err := service.RetrieveDatabase(context.Background(), "e65ccf14-e13b-48d1-a6d1-b14cd84c4bed")
+			err = fmt.Errorf("loading the schema: %w", err)
+
+			if got := IsNotFound(err); got != tt.wantNotFound {
+				t.Errorf("IsNotFound(%v) = %v, want %v", err, got, tt.wantNotFound)
+			}
+			if got := IsUnauthorized(err); got != tt.wantUnauthorized {
+				t.Errorf("IsUnauthorized(%v) = %v, want %v", err, got, tt.wantUnauthorized)
+			}
+		})
+	}
+}
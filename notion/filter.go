@@ -0,0 +1,110 @@
+package notion
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// FilterFromMap builds a Filter from a generic spec, e.g. one read from a YAML or JSON config file
+//
+// The spec has the shape of the filter object accepted by the API, e.g.
+//
+//	{"property": "Done", "checkbox": {"equals": true}}
+//
+// Compound specs with the "and" or "or" lists of filters are supported as well. The spec is validated: single property
+// filters need the property and exactly one condition, and the unknown fields or values of a wrong type are rejected.
+func FilterFromMap(spec map[string]interface{}) (*Filter, error) {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter spec: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	filter := &Filter{}
+	if err := dec.Decode(filter); err != nil {
+		return nil, fmt.Errorf("invalid filter spec: %w", err)
+	}
+	if err := filter.validate(); err != nil {
+		return nil, fmt.Errorf("invalid filter spec: %w", err)
+	}
+	return filter, nil
+}
+
+func (f *Filter) validate() error {
+	if f.CompoundFilter != nil {
+		if f.Property != "" || f.conditions() > 0 {
+			return fmt.Errorf("compound filter can't have a property or a condition")
+		}
+		if (len(f.And) > 0) == (len(f.Or) > 0) {
+			return fmt.Errorf("compound filter needs either and or or filters")
+		}
+		for _, nested := range append(f.And, f.Or...) {
+			if err := nested.validate(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if f.Timestamp != "" {
+		return f.validateTimestamp()
+	}
+	if f.Property == "" {
+		return fmt.Errorf("missing property")
+	}
+	if n := f.conditions(); n != 1 {
+		return fmt.Errorf("property %s needs exactly one condition, got %d", f.Property, n)
+	}
+	return nil
+}
+
+func (f *Filter) conditions() int {
+	n := 0
+	if f.Title != nil {
+		n++
+	}
+	if f.RichText != nil {
+		n++
+	}
+	if f.Checkbox != nil {
+		n++
+	}
+	if f.Select != nil {
+		n++
+	}
+	if f.Number != nil {
+		n++
+	}
+	if f.Date != nil {
+		n++
+	}
+	if f.CreatedTime != nil {
+		n++
+	}
+	if f.LastEditedTime != nil {
+		n++
+	}
+	if f.Status != nil {
+		n++
+	}
+	if f.Relation != nil {
+		n++
+	}
+	return n
+}
+
+func (f *Filter) validateTimestamp() error {
+	if f.Property != "" {
+		return fmt.Errorf("timestamp filter can't have a property")
+	}
+	if n := f.conditions(); n != 1 {
+		return fmt.Errorf("timestamp %s needs exactly one condition, got %d", f.Timestamp, n)
+	}
+	switch {
+	case f.Timestamp == "created_time" && f.CreatedTime != nil:
+	case f.Timestamp == "last_edited_time" && f.LastEditedTime != nil:
+	default:
+		return fmt.Errorf("timestamp %s needs a %s condition", f.Timestamp, f.Timestamp)
+	}
+	return nil
+}
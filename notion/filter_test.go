@@ -0,0 +1,142 @@
+package notion
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFilterFromMap(t *testing.T) {
+	tests := []struct {
+		name       string
+		spec       map[string]interface{}
+		want       string
+		wantErrMsg string
+	}{
+		{
+			name: "should build a checkbox filter",
+			spec: map[string]interface{}{
+				"property": "Done",
+				"checkbox": map[string]interface{}{"equals": true},
+			},
+			want: `{"property":"Done","checkbox":{"equals":true}}`,
+		},
+		{
+			name: "should build a number filter",
+			spec: map[string]interface{}{
+				"property": "Price",
+				"number":   map[string]interface{}{"greater_than": 0},
+			},
+			want: `{"property":"Price","number":{"greater_than":0}}`,
+		},
+		{
+			name: "should build a status filter",
+			spec: map[string]interface{}{
+				"property": "Status",
+				"status":   map[string]interface{}{"equals": "In progress"},
+			},
+			want: `{"property":"Status","status":{"equals":"In progress"}}`,
+		},
+		{
+			name: "should build a timestamp filter",
+			spec: map[string]interface{}{
+				"timestamp":        "last_edited_time",
+				"last_edited_time": map[string]interface{}{"past_week": map[string]interface{}{}},
+			},
+			want: `{"timestamp":"last_edited_time","last_edited_time":{"past_week":{}}}`,
+		},
+		{
+			name: "should reject a timestamp filter with a mismatched condition",
+			spec: map[string]interface{}{
+				"timestamp":        "created_time",
+				"last_edited_time": map[string]interface{}{"past_week": map[string]interface{}{}},
+			},
+			wantErrMsg: "timestamp created_time needs a created_time condition",
+		},
+		{
+			name: "should build a relative date filter",
+			spec: map[string]interface{}{
+				"property": "Due",
+				"date":     map[string]interface{}{"next_week": map[string]interface{}{}},
+			},
+			want: `{"property":"Due","date":{"next_week":{}}}`,
+		},
+		{
+			name: "should build a compound filter",
+			spec: map[string]interface{}{
+				"or": []interface{}{
+					map[string]interface{}{"property": "Done", "checkbox": map[string]interface{}{"equals": true}},
+					map[string]interface{}{"property": "Price", "number": map[string]interface{}{"less_than": 10}},
+				},
+			},
+			want: `{"or":[{"property":"Done","checkbox":{"equals":true}},{"property":"Price","number":{"less_than":10}}]}`,
+		},
+		{
+			name: "should reject an unknown condition",
+			spec: map[string]interface{}{
+				"property": "Done",
+				"checkbox": map[string]interface{}{"is_true": true},
+			},
+			wantErrMsg: `unknown field "is_true"`,
+		},
+		{
+			name: "should reject a value of a wrong type",
+			spec: map[string]interface{}{
+				"property": "Price",
+				"number":   map[string]interface{}{"greater_than": "ten"},
+			},
+			wantErrMsg: "cannot unmarshal string",
+		},
+		{
+			name: "should reject a filter without a property",
+			spec: map[string]interface{}{
+				"checkbox": map[string]interface{}{"equals": true},
+			},
+			wantErrMsg: "missing property",
+		},
+		{
+			name: "should reject a filter with two conditions",
+			spec: map[string]interface{}{
+				"property": "Done",
+				"checkbox": map[string]interface{}{"equals": true},
+				"number":   map[string]interface{}{"equals": 1},
+			},
+			wantErrMsg: "needs exactly one condition, got 2",
+		},
+		{
+			name: "should reject an invalid nested filter",
+			spec: map[string]interface{}{
+				"and": []interface{}{
+					map[string]interface{}{"property": "Done"},
+				},
+			},
+			wantErrMsg: "needs exactly one condition, got 0",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			gotFilter, gotErr := FilterFromMap(tt.spec)
+			if tt.wantErrMsg != "" {
+				if gotErr == nil {
+					gotErr = fmt.Errorf("no error")
+				}
+				if !strings.Contains(gotErr.Error(), tt.wantErrMsg) {
+					t.Errorf("FilterFromMap() error = %v, wantErr %v", gotErr, tt.wantErrMsg)
+				}
+				return
+			}
+			if gotErr != nil {
+				t.Fatalf("FilterFromMap() error = %v, wantErr <nil>", gotErr)
+			}
+			got, err := json.Marshal(gotFilter)
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("FilterFromMap() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package notion
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"notion-go/client"
+)
+
+// ClientError represents a problem detected by the client itself, e.g. an invalid argument caught before making the
+// request
+type ClientError = client.LocalError
+
+var idPattern = regexp.MustCompile(
+	`^(?i:[0-9a-f]{32}|[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})$`,
+)
+
+// validateID checks that id is a Notion UUID, either hyphenated or compact (32 hex digits), so that a malformed id
+// fails before the round trip to the API
+func validateID(id string) error {
+	if !idPattern.MatchString(id) {
+		return ClientError{Reason: fmt.Sprintf("invalid id %q, want a UUID", id)}
+	}
+	return nil
+}
+
+// normalizeID validates id and converts it to the canonical, lower case and hyphenated form, so that the request paths
+// are the same regardless of how the caller formatted the id
+func normalizeID(id string) (string, error) {
+	if err := validateID(id); err != nil {
+		return "", err
+	}
+	compact := strings.ToLower(strings.ReplaceAll(id, "-", ""))
+	return fmt.Sprintf("%s-%s-%s-%s-%s", compact[:8], compact[8:12], compact[12:16], compact[16:20], compact[20:]), nil
+}
@@ -0,0 +1,110 @@
+package notion
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestValidateID(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      string
+		wantErr bool
+	}{
+		{
+			name: "should accept a hyphenated id",
+			id:   "e65ccf14-e13b-48d1-a6d1-b14cd84c4bed",
+		},
+		{
+			name: "should accept a compact id",
+			id:   "e65ccf14e13b48d1a6d1b14cd84c4bed",
+		},
+		{
+			name: "should accept an upper case id",
+			id:   "E65CCF14-E13B-48D1-A6D1-B14CD84C4BED",
+		},
+		{
+			name:    "should reject a malformed id",
+			id:      "not-uuid",
+			wantErr: true,
+		},
+		{
+			name:    "should reject a truncated id",
+			id:      "e65ccf14-e13b-48d1-a6d1-b14cd84c4be",
+			wantErr: true,
+		},
+		{
+			name:    "should reject an empty id",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateID(tt.id)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateID(%q) error = %v, wantErr %v", tt.id, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestService_RetrieveDatabase_InvalidID(t *testing.T) {
+	requests := 0
+	httpClient, _ := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+		requests++
+		return nil, fmt.Errorf("unexpected request")
+	})
+	service := New("token", WithHTTPClient(httpClient))
+
+	_, err := service.RetrieveDatabase(context.Background(), "not-uuid")
+
+	var clientErr ClientError
+	if !errors.As(err, &clientErr) {
+		t.Errorf("RetrieveDatabase() error = %v, want ClientError", err)
+	}
+	if requests != 0 {
+		t.Errorf("requests = %d, want 0", requests)
+	}
+}
+
+func TestNormalizeID(t *testing.T) {
+	want := "e65ccf14-e13b-48d1-a6d1-b14cd84c4bed"
+	for _, id := range []string{
+		"e65ccf14-e13b-48d1-a6d1-b14cd84c4bed",
+		"e65ccf14e13b48d1a6d1b14cd84c4bed",
+		"E65CCF14E13B48D1A6D1B14CD84C4BED",
+	} {
+		got, err := normalizeID(id)
+		if err != nil {
+			t.Errorf("normalizeID(%q) error = %v, wantErr <nil>", id, err)
+		}
+		if got != want {
+			t.Errorf("normalizeID(%q) = %v, want %v", id, got, want)
+		}
+	}
+}
+
+func TestService_RetrievePage_CompactID(t *testing.T) {
+	httpClient, capturedRequest := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"object":"page","id":"251d2b5f-268c-4de2-afe9-c71ff92ca95c"}`)),
+		}, nil
+	})
+	service := New("token", WithHTTPClient(httpClient))
+
+	if _, err := service.RetrievePage(context.Background(), "251d2b5f268c4de2afe9c71ff92ca95c"); err != nil {
+		t.Fatalf("RetrievePage() error = %v, wantErr <nil>", err)
+	}
+
+	wantPath := "/v1/pages/251d2b5f-268c-4de2-afe9-c71ff92ca95c"
+	if gotPath := capturedRequest.URL.Path; gotPath != wantPath {
+		t.Errorf("path = %v, want %v", gotPath, wantPath)
+	}
+}
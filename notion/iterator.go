@@ -0,0 +1,137 @@
+package notion
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// PageIterator streams pages from a paginated endpoint
+//
+// The next page of results is fetched only once all the pages from the current one were consumed:
+//
+//	it := s.QueryDatabaseIterator(ctx, databaseID, filter, sorts)
+//	for it.Next(ctx) {
+//		page := it.Page()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type PageIterator struct {
+	ctx      context.Context
+	fetch    func(ctx context.Context, cursor string) (*PageList, error)
+	maxPages int
+	fetched  int
+	buf      []Page
+	cursor   string
+	done     bool
+	page     Page
+	err      error
+}
+
+// Next advances the iterator to the next page, fetching more results if needed
+//
+// It returns false when there are no more pages or when fetching failed, check Err to tell these apart. The ctx bounds
+// the request made by this call, while the ctx given when creating the iterator bounds the whole iteration. Hitting
+// the limit set with WithMaxPages stops the iteration with ErrMaxPagesExceeded.
+func (it *PageIterator) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+	for len(it.buf) == 0 {
+		if it.done {
+			return false
+		}
+		if err := it.ctx.Err(); err != nil {
+			it.err = err
+			return false
+		}
+		if it.maxPages > 0 && it.fetched >= it.maxPages {
+			it.err = ErrMaxPagesExceeded
+			return false
+		}
+		result, err := it.fetch(ctx, it.cursor)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.fetched++
+		it.buf = result.Results
+		it.cursor = result.NextCursor
+		it.done = !result.HasMore
+	}
+	it.page, it.buf = it.buf[0], it.buf[1:]
+	return true
+}
+
+// Page returns the current page, call it after Next returned true
+func (it *PageIterator) Page() Page {
+	return it.page
+}
+
+// Err returns the error which stopped the iteration, if any
+func (it *PageIterator) Err() error {
+	return it.err
+}
+
+// QueryDatabaseIterator returns an iterator over all the pages from the given database matching the filter
+//
+// See QueryDatabase.
+func (s *Service) QueryDatabaseIterator(ctx context.Context, databaseID string, filter *Filter, sorts []Sort) *PageIterator {
+	return &PageIterator{
+		ctx:      ctx,
+		maxPages: s.maxPages,
+		fetch: func(ctx context.Context, cursor string) (*PageList, error) {
+			return s.QueryDatabase(ctx, databaseID, filter, sorts, &Pagination{StartCursor: cursor, PageSize: defaultPageSize})
+		},
+	}
+}
+
+// ChangeFeed returns an iterator over the pages from the given database edited since the given time
+//
+// The pages are streamed in the ascending last edited time order. The pages last edited exactly at since are skipped,
+// so the last edited time of the last page seen can be used as since to resume the feed. The database needs to have
+// a property of type "last_edited_time", it's used to filter the pages.
+func (s *Service) ChangeFeed(ctx context.Context, databaseID string, since time.Time) (*PageIterator, error) {
+	db, err := s.RetrieveDatabase(ctx, databaseID)
+	if err != nil {
+		return nil, err
+	}
+	var lastEdited string
+	for name, prop := range db.Properties {
+		if prop.Type == "last_edited_time" {
+			lastEdited = name
+			break
+		}
+	}
+	if lastEdited == "" {
+		return nil, fmt.Errorf("database %s has no last_edited_time property", databaseID)
+	}
+
+	filter := &Filter{
+		Property:       lastEdited,
+		LastEditedTime: &DateFilterCondition{OnOrAfter: since.UTC().Format(time.RFC3339)},
+	}
+	sorts := []Sort{{Timestamp: "last_edited_time", Direction: SortAsc}}
+	return &PageIterator{
+		ctx:      ctx,
+		maxPages: s.maxPages,
+		fetch: func(ctx context.Context, cursor string) (*PageList, error) {
+			result, err := s.QueryDatabase(ctx, databaseID, filter, sorts, &Pagination{StartCursor: cursor, PageSize: defaultPageSize})
+			if err != nil {
+				return nil, err
+			}
+			changed := result.Results[:0]
+			for _, page := range result.Results {
+				edited, err := time.Parse(time.RFC3339, page.LastEditedTime)
+				if err == nil && edited.Equal(since) {
+					continue
+				}
+				changed = append(changed, page)
+			}
+			result.Results = changed
+			return result, nil
+		},
+	}, nil
+}
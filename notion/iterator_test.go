@@ -0,0 +1,115 @@
+package notion
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestService_QueryDatabaseIterator(t *testing.T) {
+	requests := 0
+	httpClient, _ := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+		requests++
+		payload, _ := ioutil.ReadAll(req.Body)
+		respBody := `{"object":"list","results":[{"id":"page-1"},{"id":"page-2"}],"next_cursor":"cursor-2","has_more":true}`
+		if strings.Contains(string(payload), "cursor-2") {
+			respBody = `{"object":"list","results":[{"id":"page-3"}],"next_cursor":null,"has_more":false}`
+		}
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(respBody)),
+		}, nil
+	})
+	service := WithCustomHttpClient("token", httpClient, false)
+	ctx := context.Background()
+
+	it := service.QueryDatabaseIterator(ctx, "e65ccf14-e13b-48d1-a6d1-b14cd84c4bed", nil, nil)
+
+	if requests != 0 {
+		t.Errorf("requests before the first Next() = %d, want 0", requests)
+	}
+	var gotIDs []string
+	for it.Next(ctx) {
+		gotIDs = append(gotIDs, it.Page().ID)
+		if len(gotIDs) == 2 && requests != 1 {
+			t.Errorf("requests after consuming the first result page = %d, want 1", requests)
+		}
+	}
+	if err := it.Err(); err != nil {
+		t.Errorf("Err() = %v, want <nil>", err)
+	}
+	if it.Next(ctx) {
+		t.Errorf("Next() after the final page = true, want false")
+	}
+
+	wantIDs := []string{"page-1", "page-2", "page-3"}
+	if diff := cmp.Diff(wantIDs, gotIDs); diff != "" {
+		t.Errorf("iterated pages mismatch (-want +got):\n%s", diff)
+	}
+	if requests != 2 {
+		t.Errorf("requests = %d, want 2", requests)
+	}
+}
+
+func TestService_ChangeFeed(t *testing.T) {
+	var gotPayload string
+	httpClient, _ := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+		respBody := `{
+		  "object": "database",
+		  "id": "e65ccf14-e13b-48d1-a6d1-b14cd84c4bed",
+		  "properties": {
+			"Date Edited": {"id": "M[oR", "type": "last_edited_time", "last_edited_time": {}},
+			"Name": {"id": "title", "type": "title", "title": {}}
+		  }
+		}`
+		if req.Method == http.MethodPost {
+			payload, _ := ioutil.ReadAll(req.Body)
+			gotPayload = string(payload)
+			respBody = `{
+			  "object": "list",
+			  "results": [
+				{"object": "page", "id": "page-1", "last_edited_time": "2021-05-20T09:19:00.000Z"},
+				{"object": "page", "id": "page-2", "last_edited_time": "2021-05-20T09:20:00.000Z"},
+				{"object": "page", "id": "page-3", "last_edited_time": "2021-05-21T11:00:00.000Z"}
+			  ],
+			  "next_cursor": null,
+			  "has_more": false
+			}`
+		}
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(respBody)),
+		}, nil
+	})
+	service := New("token", WithHTTPClient(httpClient))
+	ctx := context.Background()
+	since := time.Date(2021, 5, 20, 9, 19, 0, 0, time.UTC)
+
+	it, err := service.ChangeFeed(ctx, "e65ccf14-e13b-48d1-a6d1-b14cd84c4bed", since)
+	if err != nil {
+		t.Fatalf("ChangeFeed() error = %v, wantErr <nil>", err)
+	}
+	var gotIDs []string
+	for it.Next(ctx) {
+		gotIDs = append(gotIDs, it.Page().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Errorf("Err() = %v, want <nil>", err)
+	}
+
+	wantPayload := `{"filter":{"property":"Date Edited","last_edited_time":{"on_or_after":"2021-05-20T09:19:00Z"}},` +
+		`"sorts":[{"timestamp":"last_edited_time","direction":"ascending"}],"page_size":100}`
+	if gotPayload != wantPayload {
+		t.Errorf("payload = %s, want %s", gotPayload, wantPayload)
+	}
+	wantIDs := []string{"page-2", "page-3"}
+	if diff := cmp.Diff(wantIDs, gotIDs); diff != "" {
+		t.Errorf("iterated pages mismatch (-want +got):\n%s", diff)
+	}
+}
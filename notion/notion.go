@@ -2,7 +2,12 @@ package notion
 
 import (
 	"fmt"
+	"io"
+	"log"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
 
 	"notion-go/client"
 )
@@ -10,30 +15,262 @@ import (
 const version = "2021-05-13"
 const root = "https://api.notion.com/v1"
 
+// DeprecationLogger receives the notices about using deprecated APIs, set it to nil to silence them
+//
+// Each notice is logged at most once per process.
+var DeprecationLogger = log.Default()
+
+var deprecationNotice sync.Once
+
 // Service is the facade for the notion API
 type Service struct {
-	client *client.Client
-	token  string
+	client    *client.Client
+	token     string
+	maxPages  int
+	cfg       *config
+	databases *databaseCache
 }
 
-// New creates a Service
-func New(token string, trace bool) *Service {
-	return WithCustomHttpClient(token, http.DefaultClient, trace)
+// Option customizes the Service created by New
+type Option func(*config)
+
+type config struct {
+	httpClient *http.Client
+	client     client.Options
+	maxPages   int
 }
 
-// WithCustomHttpClient creates a Service using the custom http.Client
-func WithCustomHttpClient(token string, httpClient *http.Client, trace bool) *Service {
+// WithTrace makes the Service log all the requests and responses to stderr, with the API token redacted
+func WithTrace() Option {
+	return func(c *config) {
+		c.client.Trace = true
+	}
+}
+
+// WithTraceWriter makes the Service log all the requests and responses to w, with the API token redacted
+func WithTraceWriter(w io.Writer) Option {
+	return func(c *config) {
+		c.client.Trace = true
+		c.client.TraceWriter = w
+	}
+}
+
+// WithHTTPClient makes the Service use a custom http.Client
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *config) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithNotionVersion makes the Service send the given Notion-Version header instead of the one the package is written
+// for
+func WithNotionVersion(notionVersion string) Option {
+	return func(c *config) {
+		c.client.AddHeaders["Notion-Version"] = notionVersion
+	}
+}
+
+// WithHeaders makes the Service send the extra headers with every request, e.g. the ones required by a proxy
+//
+// The headers can't override the ones already set, e.g. Authorization or Notion-Version.
+func WithHeaders(headers map[string]string) Option {
+	return func(c *config) {
+		set := make(map[string]bool, len(c.client.AddHeaders))
+		for header := range c.client.AddHeaders {
+			set[http.CanonicalHeaderKey(header)] = true
+		}
+		for header, val := range headers {
+			if set[http.CanonicalHeaderKey(header)] {
+				continue
+			}
+			c.client.AddHeaders[header] = val
+		}
+	}
+}
+
+// WithBaseURL makes the Service send the requests to baseURL instead of https://api.notion.com/v1, e.g. to a local mock
+// server in tests
+func WithBaseURL(baseURL string) Option {
+	return func(c *config) {
+		c.client.RootURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithMaxRetries makes the Service retry the rate-limited, unavailable and failed on the network requests up to n times
+//
+// The server errors of the POST requests, e.g. CreatePage, are not retried to avoid creating duplicates.
+func WithMaxRetries(n int) Option {
+	return func(c *config) {
+		c.client.MaxRetries = n
+	}
+}
+
+// WithRetryOnConflict makes the Service retry the requests which failed with the conflict_error, e.g. because of
+// a concurrent update of the same page, see WithMaxRetries
+func WithRetryOnConflict() Option {
+	return func(c *config) {
+		c.client.RetryOnConflict = true
+	}
+}
+
+// WithBackoffBase sets the delay before the first retry of a request which failed on the network, it doubles with
+// every next retry
+func WithBackoffBase(d time.Duration) Option {
+	return func(c *config) {
+		c.client.BackoffBase = d
+	}
+}
+
+// WithHedgeDelay makes the Service send a second, identical GET request if there's no response within the delay
+//
+// The response which comes first is used and the other request is cancelled.
+func WithHedgeDelay(d time.Duration) Option {
+	return func(c *config) {
+		c.client.HedgeDelay = d
+	}
+}
+
+// WithWarningHandler makes the Service call f with the warnings sent by the API, e.g. about deprecated endpoints
+//
+// By default the warnings are logged.
+func WithWarningHandler(f func(method, path, warning string)) Option {
+	return func(c *config) {
+		c.client.OnWarning = f
+	}
+}
+
+// WithTimeout limits the time of each API request to d, a shorter deadline of the context passed to a call still
+// applies
+func WithTimeout(d time.Duration) Option {
+	return func(c *config) {
+		c.client.Timeout = d
+	}
+}
+
+// WithMiddleware wraps the transport of the http client in the middleware, e.g. to trace every API call
+//
+// The first middleware is the outermost one. The option can be used multiple times, the middleware accumulate.
+func WithMiddleware(middleware ...func(http.RoundTripper) http.RoundTripper) Option {
+	return func(c *config) {
+		c.client.Middleware = append(c.client.Middleware, middleware...)
+	}
+}
+
+// WithLogger makes the Service report every API request, with its status code and duration, to the logger
+func WithLogger(logger client.Logger) Option {
+	return func(c *config) {
+		c.client.Logger = logger
+	}
+}
+
+// WithQuotaHandler makes the Service call f with the remaining request quota whenever the API reports it
+func WithQuotaHandler(f func(method, path string, remaining int)) Option {
+	return func(c *config) {
+		c.client.OnQuota = f
+	}
+}
+
+// WithStrictDecode makes the Service fail on the response fields it doesn't know, e.g. to catch a mismatch between
+// the Notion-Version sent and the shape of the responses
+//
+// The items of the Search results are not checked, they are decoded as a page or a database only once their object
+// type is known.
+func WithStrictDecode() Option {
+	return func(c *config) {
+		c.client.StrictDecode = true
+	}
+}
+
+// WithRequestIDHeader makes the Service send the request ID set on the context with client.WithRequestID in the header,
+// e.g. to pass a correlation ID on to Notion
+func WithRequestIDHeader(header string) Option {
+	return func(c *config) {
+		c.client.RequestIDHeader = header
+	}
+}
+
+// WithMaxPages limits the number of result pages fetched by the auto-paginating helpers to n
+//
+// The helpers return the results fetched so far with ErrMaxPagesExceeded once the limit is hit. Zero means no limit.
+func WithMaxPages(n int) Option {
+	return func(c *config) {
+		c.maxPages = n
+	}
+}
+
+// New creates a Service customized with the given options
+//
+// By default it uses http.DefaultClient, talks to https://api.notion.com/v1 and doesn't trace, retry nor time out the
+// requests.
+//
+// It replaces New(token, trace), use New(token, WithTrace()) to trace the requests.
+func New(token string, opts ...Option) *Service {
+	return newService(token, newConfig(token, opts...), newDatabaseCache())
+}
+
+// With returns a copy of the Service with the options overridden, e.g. to trace a single call
+//
+// The copy shares the http client, the token and the cache of the retrieved databases with the Service, which is left
+// unchanged.
+func (s *Service) With(opts ...Option) *Service {
+	cfg := s.cfg.clone()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return newService(s.token, cfg, s.databases)
+}
+
+func newService(token string, cfg *config, databases *databaseCache) *Service {
 	return &Service{
-		client: client.New(
-			httpClient,
-			client.Options{
-				AddHeaders: map[string]string{
-					"Authorization":  fmt.Sprintf("Bearer %v", token),
-					"Notion-Version": version,
-				},
-				RootURL: root,
-				Trace:   trace,
+		client:    client.New(cfg.httpClient, cfg.client),
+		token:     token,
+		maxPages:  cfg.maxPages,
+		cfg:       cfg,
+		databases: databases,
+	}
+}
+
+func newConfig(token string, opts ...Option) *config {
+	cfg := &config{
+		httpClient: http.DefaultClient,
+		client: client.Options{
+			AddHeaders: map[string]string{
+				"Authorization":  fmt.Sprintf("Bearer %v", token),
+				"Notion-Version": version,
 			},
-		),
+			RootURL: root,
+		},
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// clone copies the config deep enough for the options applied to the copy not to change the original
+func (c *config) clone() *config {
+	cp := *c
+	cp.client.AddHeaders = make(map[string]string, len(c.client.AddHeaders))
+	for header, val := range c.client.AddHeaders {
+		cp.client.AddHeaders[header] = val
+	}
+	cp.client.Middleware = append([]func(http.RoundTripper) http.RoundTripper(nil), c.client.Middleware...)
+	return &cp
+}
+
+// WithCustomHttpClient creates a Service using the custom http.Client
+//
+// Deprecated: use New(token, WithHTTPClient(httpClient), WithTrace()) instead.
+func WithCustomHttpClient(token string, httpClient *http.Client, trace bool) *Service {
+	deprecationNotice.Do(func() {
+		if DeprecationLogger != nil {
+			DeprecationLogger.Print("notion: WithCustomHttpClient(token, httpClient, trace) is deprecated, " +
+				"use New(token, opts...) instead")
+		}
+	})
+	opts := []Option{WithHTTPClient(httpClient)}
+	if trace {
+		opts = append(opts, WithTrace())
 	}
+	return New(token, opts...)
 }
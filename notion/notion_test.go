@@ -0,0 +1,229 @@
+package notion
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestWithCustomHttpClient_DeprecationNotice(t *testing.T) {
+	defer func(logger *log.Logger) {
+		DeprecationLogger = logger
+	}(DeprecationLogger)
+
+	tests := []struct {
+		name        string
+		silence     bool
+		wantNotices int
+	}{
+		{
+			name:        "should log the notice once",
+			wantNotices: 1,
+		},
+		{
+			name:        "should not log the notice when silenced",
+			silence:     true,
+			wantNotices: 0,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			DeprecationLogger = log.New(&buf, "", 0)
+			if tt.silence {
+				DeprecationLogger = nil
+			}
+			deprecationNotice = sync.Once{}
+
+			WithCustomHttpClient("token", http.DefaultClient, true)
+			WithCustomHttpClient("token", http.DefaultClient, false)
+
+			gotNotices := strings.Count(buf.String(), "deprecated")
+			if gotNotices != tt.wantNotices {
+				t.Errorf("logged %d notices, want %d; log:\n%s", gotNotices, tt.wantNotices, buf.String())
+			}
+		})
+	}
+}
+
+func TestNew_Options(t *testing.T) {
+	httpClient := &http.Client{}
+	tests := []struct {
+		name  string
+		opts  []Option
+		check func(t *testing.T, cfg *config)
+	}{
+		{
+			name: "should default to the public API",
+			check: func(t *testing.T, cfg *config) {
+				if cfg.client.RootURL != root || cfg.client.Trace || cfg.httpClient != http.DefaultClient {
+					t.Errorf("config = %+v, want the defaults", cfg)
+				}
+				if got := cfg.client.AddHeaders["Authorization"]; got != "Bearer token" {
+					t.Errorf("Authorization = %v, want Bearer token", got)
+				}
+				if got := cfg.client.AddHeaders["Notion-Version"]; got != version {
+					t.Errorf("Notion-Version = %v, want %v", got, version)
+				}
+			},
+		},
+		{
+			name: "should enable the trace",
+			opts: []Option{WithTrace()},
+			check: func(t *testing.T, cfg *config) {
+				if !cfg.client.Trace {
+					t.Errorf("Trace = false, want true")
+				}
+			},
+		},
+		{
+			name: "should set the base URL",
+			opts: []Option{WithBaseURL("http://localhost:8080/v1")},
+			check: func(t *testing.T, cfg *config) {
+				if cfg.client.RootURL != "http://localhost:8080/v1" {
+					t.Errorf("RootURL = %v, want http://localhost:8080/v1", cfg.client.RootURL)
+				}
+			},
+		},
+		{
+			name: "should set the http client",
+			opts: []Option{WithHTTPClient(httpClient)},
+			check: func(t *testing.T, cfg *config) {
+				if cfg.httpClient != httpClient {
+					t.Errorf("httpClient = %p, want %p", cfg.httpClient, httpClient)
+				}
+			},
+		},
+		{
+			name: "should set the Notion version",
+			opts: []Option{WithNotionVersion("2022-02-22")},
+			check: func(t *testing.T, cfg *config) {
+				if got := cfg.client.AddHeaders["Notion-Version"]; got != "2022-02-22" {
+					t.Errorf("Notion-Version = %v, want 2022-02-22", got)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tt.check(t, newConfig("token", tt.opts...))
+		})
+	}
+}
+
+func TestWithMiddleware(t *testing.T) {
+	var gotURL, gotVersion string
+	interceptor := func(next http.RoundTripper) http.RoundTripper {
+		return RequestToResponse(func(req *http.Request) (*http.Response, error) {
+			gotURL = req.URL.String()
+			gotVersion = req.Header.Get("Notion-Version")
+			return next.RoundTrip(req)
+		})
+	}
+	httpClient := &http.Client{Transport: RequestToResponse(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"object":"database","id":"e65ccf14-e13b-48d1-a6d1-b14cd84c4bed"}`)),
+		}, nil
+	})}
+	service := New("token", WithHTTPClient(httpClient), WithMiddleware(interceptor))
+
+	if _, err := service.RetrieveDatabase(context.Background(), "e65ccf14-e13b-48d1-a6d1-b14cd84c4bed"); err != nil {
+		t.Fatalf("RetrieveDatabase() error = %v, wantErr <nil>", err)
+	}
+
+	wantURL := "https://api.notion.com/v1/databases/e65ccf14-e13b-48d1-a6d1-b14cd84c4bed"
+	if gotURL != wantURL {
+		t.Errorf("intercepted url = %v, want %v", gotURL, wantURL)
+	}
+	if gotVersion != version {
+		t.Errorf("intercepted Notion-Version = %v, want %v", gotVersion, version)
+	}
+}
+
+func TestWithBaseURL(t *testing.T) {
+	httpClient, capturedRequest := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"object":"user","id":"9188c6a5-7381-452f-b3dc-d4865aa89bdf"}`)),
+		}, nil
+	})
+	service := New("token", WithHTTPClient(httpClient), WithBaseURL("http://localhost:8080/v1/"))
+
+	if _, err := service.RetrieveBotUser(context.Background()); err != nil {
+		t.Fatalf("RetrieveBotUser() error = %v, wantErr <nil>", err)
+	}
+
+	wantURL := "http://localhost:8080/v1/users/me"
+	if gotURL := capturedRequest.URL.String(); gotURL != wantURL {
+		t.Errorf("url = %v, want %v", gotURL, wantURL)
+	}
+}
+
+func TestWithHeaders(t *testing.T) {
+	httpClient, capturedRequest := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"object":"user","id":"9188c6a5-7381-452f-b3dc-d4865aa89bdf"}`)),
+		}, nil
+	})
+	service := New("token", WithHTTPClient(httpClient), WithHeaders(map[string]string{
+		"X-My-Header":   "my-value",
+		"authorization": "Bearer other-token",
+	}))
+
+	if _, err := service.RetrieveBotUser(context.Background()); err != nil {
+		t.Fatalf("RetrieveBotUser() error = %v, wantErr <nil>", err)
+	}
+
+	if got := capturedRequest.Header.Get("X-My-Header"); got != "my-value" {
+		t.Errorf("X-My-Header = %q, want %q", got, "my-value")
+	}
+	if got := capturedRequest.Header.Values("Authorization"); len(got) != 1 || got[0] != "Bearer token" {
+		t.Errorf("Authorization = %q, want [%q]", got, "Bearer token")
+	}
+}
+
+func TestService_With(t *testing.T) {
+	var gotVersions []string
+	httpClient := &http.Client{Transport: RequestToResponse(func(req *http.Request) (*http.Response, error) {
+		gotVersions = append(gotVersions, req.Header.Get("Notion-Version"))
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"object":"user","id":"9188c6a5-7381-452f-b3dc-d4865aa89bdf"}`)),
+		}, nil
+	})}
+	service := New("token", WithHTTPClient(httpClient))
+	var trace bytes.Buffer
+	traced := service.With(WithTraceWriter(&trace), WithNotionVersion("2022-02-22"))
+
+	if _, err := service.RetrieveBotUser(context.Background()); err != nil {
+		t.Fatalf("RetrieveBotUser() error = %v, wantErr <nil>", err)
+	}
+	if trace.Len() != 0 {
+		t.Errorf("original service traced %q, want nothing", trace.String())
+	}
+	if _, err := traced.RetrieveBotUser(context.Background()); err != nil {
+		t.Fatalf("RetrieveBotUser() error = %v, wantErr <nil>", err)
+	}
+	if !strings.Contains(trace.String(), "GET /v1/users/me") {
+		t.Errorf("trace = %q, want the request traced", trace.String())
+	}
+
+	if service.cfg.client.Trace || service.cfg.client.AddHeaders["Notion-Version"] != version {
+		t.Errorf("original options = %+v, want them unchanged", service.cfg.client)
+	}
+	if wantVersions := []string{version, "2022-02-22"}; strings.Join(gotVersions, ",") != strings.Join(wantVersions, ",") {
+		t.Errorf("Notion-Version = %v, want %v", gotVersions, wantVersions)
+	}
+	if traced.cfg.httpClient != service.cfg.httpClient || traced.token != service.token {
+		t.Errorf("copy doesn't share the http client and the token with the original")
+	}
+}
@@ -1,5 +1,16 @@
 package notion
 
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
 // Page represents the properties of a single page
 //
 // See also https://developers.notion.com/reference/page
@@ -13,6 +24,48 @@ type Page struct {
 	Properties     map[string]PropertyValue `json:"properties,omitempty"`
 }
 
+// CreatedAt parses the CreatedTime of the page
+func (p *Page) CreatedAt() (time.Time, error) {
+	return parseTimestamp(p.CreatedTime)
+}
+
+// LastEditedAt parses the LastEditedTime of the page
+func (p *Page) LastEditedAt() (time.Time, error) {
+	return parseTimestamp(p.LastEditedTime)
+}
+
+// truncatedItems is the number of items after which the page object truncates the property values
+const truncatedItems = 25
+
+// truncatingTypes are the types of the properties whose values list other objects and are truncated in the page object
+// past truncatedItems of them, both Page.HasMoreProperties and Database.HasMoreProperties rely on it
+var truncatingTypes = map[string]bool{"relation": true, "rollup": true, "people": true}
+
+// HasMoreProperties reports whether any of the page property values is truncated
+//
+// A relation, rollup or people value is truncated if the API sets HasMore on it, or if it lists 25 or more items. Use
+// RetrievePageProperty to fetch the complete value.
+func (p *Page) HasMoreProperties() bool {
+	for _, pv := range p.Properties {
+		if pv.truncated() {
+			return true
+		}
+	}
+	return false
+}
+
+// truncated reports whether the value may be truncated, see Page.HasMoreProperties
+func (pv PropertyValue) truncated() bool {
+	if !truncatingTypes[pv.Type] {
+		return false
+	}
+	items := len(pv.Relation)
+	if pv.Rollup != nil {
+		items = len(pv.Rollup.Array)
+	}
+	return pv.HasMore || items >= truncatedItems
+}
+
 // Parent points to a page parent
 //
 // See also https://developers.notion.com/reference/page#database-parent
@@ -24,28 +77,134 @@ type Parent struct {
 
 // PropertyValue describes the identifier, type, and value of a page property
 //
+// Number is a float64, so the integers above 2^53 lose precision.
+//
 // See also https://developers.notion.com/reference/page#all-property-values
 type PropertyValue struct {
 	ID             string                     `json:"id,omitempty"`
 	Type           string                     `json:"type,omitempty"`
 	Title          []RichText                 `json:"title,omitempty"`
 	RichText       []RichText                 `json:"rich_text,omitempty"`
-	Number         int                        `json:"number,omitempty"`
+	Number         *float64                   `json:"number,omitempty"`
 	Select         *SelectPropertyValue       `json:"select,omitempty"`
 	MultiSelect    []MultiSelectPropertyValue `json:"multi_select,omitempty"`
 	Checkbox       bool                       `json:"checkbox,omitempty"`
 	CreatedTime    string                     `json:"created_time,omitempty"`
 	LastEditedTime string                     `json:"last_edited_time,omitempty"`
+	Date           *DatePropertyValue         `json:"date,omitempty"`
+	Rollup         *RollupPropertyValue       `json:"rollup,omitempty"`
+	Relation       []RelationPropertyValue    `json:"relation,omitempty"`
+	URL            *string                    `json:"url,omitempty"`
+	Email          *string                    `json:"email,omitempty"`
+	PhoneNumber    *string                    `json:"phone_number,omitempty"`
+	Status         *SelectPropertyValue       `json:"status,omitempty"`
+	// HasMore is set by the API on the relation values truncated in the page object
+	HasMore bool `json:"has_more,omitempty"`
 	// TODO: add the other property types
 }
 
+// MarshalJSON encodes only the field matching Type, e.g. {"type":"checkbox","checkbox":false}
+//
+// The field is encoded even if it's empty, which clears the property on update. Values with an empty or unknown Type
+// are encoded as is.
+func (pv PropertyValue) MarshalJSON() ([]byte, error) {
+	type value PropertyValue
+	content, ok := pv.content()
+	if !ok {
+		return json.Marshal(value(pv))
+	}
+
+	head, err := json.Marshal(struct {
+		ID   string `json:"id,omitempty"`
+		Type string `json:"type"`
+	}{ID: pv.ID, Type: pv.Type})
+	if err != nil {
+		return nil, err
+	}
+	key, err := json.Marshal(pv.Type)
+	if err != nil {
+		return nil, err
+	}
+	val, err := json.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := bytes.NewBuffer(head[:len(head)-1])
+	buf.WriteByte(',')
+	buf.Write(key)
+	buf.WriteByte(':')
+	buf.Write(val)
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// content returns the field matching Type, the empty lists are returned as empty slices rather than nil
+func (pv PropertyValue) content() (interface{}, bool) {
+	switch pv.Type {
+	case "title":
+		if pv.Title == nil {
+			return []RichText{}, true
+		}
+		return pv.Title, true
+	case "rich_text":
+		if pv.RichText == nil {
+			return []RichText{}, true
+		}
+		return pv.RichText, true
+	case "number":
+		return pv.Number, true
+	case "select":
+		return pv.Select, true
+	case "multi_select":
+		if pv.MultiSelect == nil {
+			return []MultiSelectPropertyValue{}, true
+		}
+		return pv.MultiSelect, true
+	case "checkbox":
+		return pv.Checkbox, true
+	case "created_time":
+		return pv.CreatedTime, true
+	case "last_edited_time":
+		return pv.LastEditedTime, true
+	case "date":
+		return pv.Date, true
+	case "rollup":
+		return pv.Rollup, true
+	case "relation":
+		if pv.Relation == nil {
+			return []RelationPropertyValue{}, true
+		}
+		return pv.Relation, true
+	case "url":
+		return pv.URL, true
+	case "email":
+		return pv.Email, true
+	case "phone_number":
+		return pv.PhoneNumber, true
+	case "status":
+		return pv.Status, true
+	}
+	return nil, false
+}
+
+// NumberValue returns the value of a number property
+//
+// The second result is false if the property isn't a number or if it's empty.
+func (pv PropertyValue) NumberValue() (float64, bool) {
+	if pv.Type != "number" || pv.Number == nil {
+		return 0, false
+	}
+	return *pv.Number, true
+}
+
 // SelectPropertyValue represents the value of a select property
 //
 // See also https://developers.notion.com/reference/page#select-property-values
 type SelectPropertyValue struct {
 	ID    string `json:"id,omitempty"`
 	Name  string `json:"name,omitempty"`
-	Color string `json:"color,omitempty"`
+	Color Color  `json:"color,omitempty"`
 }
 
 // MultiSelectPropertyValue represents the value of a select property
@@ -54,5 +213,594 @@ type SelectPropertyValue struct {
 type MultiSelectPropertyValue struct {
 	ID    string `json:"id,omitempty"`
 	Name  string `json:"name,omitempty"`
-	Color string `json:"color,omitempty"`
+	Color Color  `json:"color,omitempty"`
+}
+
+// DatePropertyValue represents the value of a date property
+//
+// Start and End are ISO 8601 date or date-time strings, End is empty unless the value is a range.
+//
+// See also https://developers.notion.com/reference/page#date-property-values
+type DatePropertyValue struct {
+	Start string `json:"start,omitempty"`
+	End   string `json:"end,omitempty"`
+}
+
+// RollupPropertyValue represents the value of a rollup property
+//
+// Type is one of "number", "date" or "array". The elements of an array rollup are full property values, each with its
+// own Type, e.g. a rollup over a select property yields values with Select set.
+//
+// See also https://developers.notion.com/reference/page#rollup-property-values
+type RollupPropertyValue struct {
+	Type     string             `json:"type,omitempty"`
+	Number   *float64           `json:"number,omitempty"`
+	Date     *DatePropertyValue `json:"date,omitempty"`
+	Array    []PropertyValue    `json:"array,omitempty"`
+	Function string             `json:"function,omitempty"`
+}
+
+// RelationPropertyValue represents a single page reference in the value of a relation property
+//
+// See also https://developers.notion.com/reference/page#relation-property-values
+type RelationPropertyValue struct {
+	ID string `json:"id,omitempty"`
+}
+
+// RetrievePage retrieves a Page object using the ID specified
+//
+// If filterProperties are given only the properties with these IDs are returned.
+//
+// See https://developers.notion.com/reference/get-page
+func (s *Service) RetrievePage(ctx context.Context, pageID string, filterProperties ...string) (*Page, error) {
+	pageID, err := normalizeID(pageID)
+	if err != nil {
+		return nil, err
+	}
+	var query url.Values
+	if len(filterProperties) > 0 {
+		query = url.Values{"filter_properties": filterProperties}
+	}
+	page := &Page{}
+	apiErr := &Error{}
+	if err := s.client.DoValues(ctx, http.MethodGet, fmt.Sprintf("/pages/%s", pageID), query, nil, page, apiErr); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// CreatePageRequest describes a page to create
+//
+// See https://developers.notion.com/reference/post-page
+type CreatePageRequest struct {
+	Parent     Parent                   `json:"parent"`
+	Properties map[string]PropertyValue `json:"properties"`
+	Children   []Block                  `json:"children,omitempty"`
+}
+
+// CreatePage creates a new page as a child of the given parent page or database
+//
+// See https://developers.notion.com/reference/post-page
+func (s *Service) CreatePage(ctx context.Context, req CreatePageRequest) (*Page, error) {
+	if err := checkWritable(req.Properties); err != nil {
+		return nil, err
+	}
+	page := &Page{}
+	apiErr := &Error{}
+	if err := s.client.Do(ctx, http.MethodPost, "/pages", nil, req, page, apiErr); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// CreatePageWithContent creates a new page with the given blocks as its content in a single request
+//
+// See CreatePage.
+func (s *Service) CreatePageWithContent(
+	ctx context.Context,
+	parent Parent,
+	properties map[string]PropertyValue,
+	children []Block,
+) (*Page, error) {
+	return s.CreatePage(ctx, CreatePageRequest{Parent: parent, Properties: properties, Children: children})
+}
+
+// PropertyItem is a single value of a page property as returned by RetrievePageProperty
+//
+// Unlike in PropertyValue, the list-like values, e.g. title or relation, are split into items holding one element each.
+//
+// See https://developers.notion.com/reference/property-item-object
+type PropertyItem struct {
+	Object   string                 `json:"object,omitempty"`
+	ID       string                 `json:"id,omitempty"`
+	Type     string                 `json:"type,omitempty"`
+	Title    *RichText              `json:"title,omitempty"`
+	RichText *RichText              `json:"rich_text,omitempty"`
+	Number   *float64               `json:"number,omitempty"`
+	Relation *RelationPropertyValue `json:"relation,omitempty"`
+	// TODO: add the other property types
+}
+
+// PropertyItemList is a response to the retrieve page property endpoint
+//
+// The simple properties, e.g. number, come as a single item, which is decoded as a list with that one item.
+//
+// See https://developers.notion.com/reference/retrieve-a-page-property
+// See https://developers.notion.com/reference/pagination
+type PropertyItemList struct {
+	Object       string         `json:"object,omitempty"`
+	Results      []PropertyItem `json:"results,omitempty"`
+	NextCursor   string         `json:"next_cursor,omitempty"`
+	HasMore      bool           `json:"has_more,omitempty"`
+	PropertyItem *PropertyItem  `json:"property_item,omitempty"`
+}
+
+// UnmarshalJSON decodes either a paginated list of items or a single item
+func (l *PropertyItemList) UnmarshalJSON(data []byte) error {
+	var item PropertyItem
+	if err := json.Unmarshal(data, &item); err != nil {
+		return err
+	}
+	if item.Object == "property_item" {
+		*l = PropertyItemList{Object: "property_item", Results: []PropertyItem{item}}
+		return nil
+	}
+	type list PropertyItemList
+	if err := json.Unmarshal(data, (*list)(l)); err != nil {
+		return err
+	}
+	if l.Results == nil {
+		l.Results = []PropertyItem{}
+	}
+	return nil
+}
+
+// propertyItemResponse holds either a single property item or a paginated list of them
+//
+// RetrievePageProperty decodes into it rather than into PropertyItemList, so the strict mode knows the fields of both.
+type propertyItemResponse struct {
+	PropertyItem
+	Results    []PropertyItem `json:"results,omitempty"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+	HasMore    bool           `json:"has_more,omitempty"`
+	ListItem   *PropertyItem  `json:"property_item,omitempty"`
+}
+
+// list returns the response as a list, a single item becomes a list with that one item
+func (r *propertyItemResponse) list() *PropertyItemList {
+	if r.Object == "property_item" {
+		return &PropertyItemList{Object: "property_item", Results: []PropertyItem{r.PropertyItem}}
+	}
+	l := &PropertyItemList{
+		Object:       r.Object,
+		Results:      r.Results,
+		NextCursor:   r.NextCursor,
+		HasMore:      r.HasMore,
+		PropertyItem: r.ListItem,
+	}
+	if l.Results == nil {
+		l.Results = []PropertyItem{}
+	}
+	return l
+}
+
+// More reports whether there are more results to fetch
+func (l *PropertyItemList) More() bool {
+	return l.HasMore
+}
+
+// Cursor returns the cursor of the next page of results, see Pagination.StartCursor
+func (l *PropertyItemList) Cursor() string {
+	return l.NextCursor
+}
+
+// RetrievePageProperty retrieves the value of a single page property, paginated for the long values
+//
+// Use it to get the complete value of the title, rich_text, relation or rollup properties, which are truncated in the
+// page object.
+//
+// See https://developers.notion.com/reference/retrieve-a-page-property
+func (s *Service) RetrievePageProperty(
+	ctx context.Context,
+	pageID string,
+	propertyID string,
+	page Pagination,
+) (*PropertyItemList, error) {
+	pageID, err := normalizeID(pageID)
+	if err != nil {
+		return nil, err
+	}
+	if err := page.validate(); err != nil {
+		return nil, err
+	}
+	items := &propertyItemResponse{}
+	apiErr := &Error{}
+	if err := s.client.Do(
+		ctx,
+		http.MethodGet,
+		fmt.Sprintf("/pages/%s/properties/%s", pageID, url.PathEscape(propertyID)),
+		page.query(),
+		nil,
+		items,
+		apiErr,
+	); err != nil {
+		return nil, err
+	}
+	return items.list(), nil
+}
+
+// UpdatePage updates the given properties of a page, the properties not listed are left unchanged
+//
+// See https://developers.notion.com/reference/patch-page
+func (s *Service) UpdatePage(ctx context.Context, pageID string, properties map[string]PropertyValue) (*Page, error) {
+	pageID, err := normalizeID(pageID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkWritable(properties); err != nil {
+		return nil, err
+	}
+	payload := struct {
+		Properties map[string]PropertyValue `json:"properties"`
+	}{
+		Properties: properties,
+	}
+	page := &Page{}
+	apiErr := &Error{}
+	if err := s.client.Do(ctx, http.MethodPatch, fmt.Sprintf("/pages/%s", pageID), nil, payload, page, apiErr); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// UpdatePages updates the properties of many pages using at most concurrency parallel requests
+//
+// The updates map a page ID to the properties to set on the page, see UpdatePage. A failed update doesn't stop the
+// others, the returned map holds the error for each page ID which failed and is empty if all succeeded. The error is
+// set only if the context is done before all the updates are sent.
+func (s *Service) UpdatePages(
+	ctx context.Context,
+	updates map[string]map[string]PropertyValue,
+	concurrency int,
+) (map[string]error, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var (
+		errs = make(map[string]error)
+		ids  = make(chan string)
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+	)
+	for w := 0; w < concurrency && w < len(updates); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range ids {
+				if _, err := s.UpdatePage(ctx, id, updates[id]); err != nil {
+					mu.Lock()
+					errs[id] = err
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+Feed:
+	for id := range updates {
+		select {
+		case ids <- id:
+		case <-ctx.Done():
+			break Feed
+		}
+	}
+	close(ids)
+	wg.Wait()
+
+	return errs, ctx.Err()
+}
+
+// ResolveRelationPages retrieves all the pages referenced by a relation property of the given page
+//
+// The relation property is identified by its ID. If the page object truncates the relation, its complete value is
+// paged through with RetrievePageProperty. The related pages are fetched with at most concurrency requests in flight
+// and are returned in the order of the relation.
+func (s *Service) ResolveRelationPages(ctx context.Context, pageID, propertyID string, concurrency int) ([]*Page, error) {
+	page, err := s.RetrievePage(ctx, pageID)
+	if err != nil {
+		return nil, err
+	}
+	var relation *PropertyValue
+	for _, pv := range page.Properties {
+		if pv.ID == propertyID {
+			pv := pv
+			relation = &pv
+			break
+		}
+	}
+	if relation == nil || relation.Type != "relation" {
+		return nil, fmt.Errorf("page %s has no relation property %s", pageID, propertyID)
+	}
+	ids, err := s.relatedIDs(ctx, pageID, relation)
+	if err != nil {
+		return nil, err
+	}
+	return s.RetrievePages(ctx, ids, concurrency)
+}
+
+// relatedIDs returns the IDs of all the pages in the relation of the given page, the relation truncated in the page
+// object is retrieved with RetrievePageProperty
+func (s *Service) relatedIDs(ctx context.Context, pageID string, relation *PropertyValue) ([]string, error) {
+	if !relation.HasMore && len(relation.Relation) < truncatedItems {
+		return relation.relationIDs(), nil
+	}
+	var ids []string
+	err := paginate(ctx, s.maxPages, func(cursor string) (Paginated, error) {
+		items, err := s.RetrievePageProperty(
+			ctx,
+			pageID,
+			relation.ID,
+			Pagination{StartCursor: cursor, PageSize: defaultPageSize},
+		)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items.Results {
+			if item.Relation != nil {
+				ids = append(ids, item.Relation.ID)
+			}
+		}
+		return items, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// expandRelationsConcurrency is the number of parallel requests made by ExpandRelations, kept low as the API allows
+// about three requests per second
+const expandRelationsConcurrency = 3
+
+// ExpandRelations retrieves the pages referenced by the relation property of the page with the given name
+//
+// The related pages are returned in the order of the relation. If the page object truncates the relation, its complete
+// value is paged through with RetrievePageProperty, like in ResolveRelationPages.
+func (s *Service) ExpandRelations(ctx context.Context, p *Page, propertyName string) ([]*Page, error) {
+	relation, ok := p.Properties[propertyName]
+	if !ok || relation.Type != "relation" {
+		return nil, fmt.Errorf("page %s has no relation property %s", p.ID, propertyName)
+	}
+	ids, err := s.relatedIDs(ctx, p.ID, &relation)
+	if err != nil {
+		return nil, err
+	}
+	return s.RetrievePages(ctx, ids, expandRelationsConcurrency)
+}
+
+func (pv *PropertyValue) relationIDs() []string {
+	ids := make([]string, 0, len(pv.Relation))
+	for _, related := range pv.Relation {
+		ids = append(ids, related.ID)
+	}
+	return ids
+}
+
+// RetrievePages retrieves the pages with given IDs using at most concurrency parallel requests
+//
+// The result preserves the order of ids. The first error cancels the outstanding requests and is returned.
+func (s *Service) RetrievePages(ctx context.Context, ids []string, concurrency int) ([]*Page, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	workCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		pages    = make([]*Page, len(ids))
+		indexes  = make(chan int)
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	for w := 0; w < concurrency && w < len(ids); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				page, err := s.RetrievePage(workCtx, ids[i])
+				if err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					continue
+				}
+				pages[i] = page
+			}
+		}()
+	}
+Feed:
+	for i := range ids {
+		select {
+		case indexes <- i:
+		case <-workCtx.Done():
+			break Feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return pages, nil
+}
+
+// Flatten converts the page properties into a map of property name to a plain go value
+//
+// Text properties (title, rich_text) become a string, number a float64 (nil if empty), select the option name,
+// multi_select a []string with the option names, checkbox a bool, timestamps and dates their (start) string
+// representation, relation a []string with the related page IDs and rollups the flattened rollup value. Property types
+// which can't be flattened map to nil.
+func (p *Page) Flatten() map[string]interface{} {
+	flat := make(map[string]interface{}, len(p.Properties))
+	for name, pv := range p.Properties {
+		flat[name] = pv.flatten()
+	}
+	return flat
+}
+
+// FlattenFields works like Flatten, but includes only the named properties
+//
+// Properties which are requested but not present on the page are omitted from the result.
+func (p *Page) FlattenFields(names ...string) map[string]interface{} {
+	flat := make(map[string]interface{}, len(names))
+	for _, name := range names {
+		pv, ok := p.Properties[name]
+		if !ok {
+			continue
+		}
+		flat[name] = pv.flatten()
+	}
+	return flat
+}
+
+// PropertyString returns the text of the title, rich_text, url, email or phone_number property with the given name
+//
+// The second result is false if there's no such property or it's of another type.
+func (p *Page) PropertyString(name string) (string, bool) {
+	pv, ok := p.Properties[name]
+	if !ok {
+		return "", false
+	}
+	switch pv.Type {
+	case "title", "rich_text", "url", "email", "phone_number":
+		s, ok := pv.flatten().(string)
+		return s, ok
+	default:
+		return "", false
+	}
+}
+
+// PropertyNumber returns the value of the number property with the given name
+//
+// The second result is false if there's no such property, it's of another type or it's empty.
+func (p *Page) PropertyNumber(name string) (float64, bool) {
+	return p.Properties[name].NumberValue()
+}
+
+// PropertyBool returns the value of the checkbox property with the given name
+//
+// The second result is false if there's no such property or it's of another type.
+func (p *Page) PropertyBool(name string) (bool, bool) {
+	pv, ok := p.Properties[name]
+	if !ok || pv.Type != "checkbox" {
+		return false, false
+	}
+	return pv.Checkbox, true
+}
+
+// PropertySelect returns the name of the option selected in the select property with the given name
+//
+// The second result is false if there's no such property, it's of another type or no option is selected.
+func (p *Page) PropertySelect(name string) (string, bool) {
+	pv, ok := p.Properties[name]
+	if !ok || pv.Type != "select" || pv.Select == nil {
+		return "", false
+	}
+	return pv.Select.Name, true
+}
+
+func (pv PropertyValue) flatten() interface{} {
+	switch pv.Type {
+	case "title":
+		return PlainText(pv.Title)
+	case "rich_text":
+		return PlainText(pv.RichText)
+	case "number":
+		if pv.Number == nil {
+			return nil
+		}
+		return *pv.Number
+	case "select":
+		if pv.Select == nil {
+			return nil
+		}
+		return pv.Select.Name
+	case "status":
+		if pv.Status == nil {
+			return nil
+		}
+		return pv.Status.Name
+	case "multi_select":
+		names := make([]string, 0, len(pv.MultiSelect))
+		for _, option := range pv.MultiSelect {
+			names = append(names, option.Name)
+		}
+		return names
+	case "checkbox":
+		return pv.Checkbox
+	case "created_time":
+		return pv.CreatedTime
+	case "last_edited_time":
+		return pv.LastEditedTime
+	case "date":
+		if pv.Date == nil {
+			return nil
+		}
+		return pv.Date.Start
+	case "relation":
+		ids := make([]string, 0, len(pv.Relation))
+		for _, related := range pv.Relation {
+			ids = append(ids, related.ID)
+		}
+		return ids
+	case "url":
+		return stringValue(pv.URL)
+	case "email":
+		return stringValue(pv.Email)
+	case "phone_number":
+		return stringValue(pv.PhoneNumber)
+	case "rollup":
+		if pv.Rollup == nil {
+			return nil
+		}
+		return pv.Rollup.flatten()
+	default:
+		return nil
+	}
+}
+
+func stringValue(s *string) interface{} {
+	if s == nil {
+		return nil
+	}
+	return *s
+}
+
+func (r *RollupPropertyValue) flatten() interface{} {
+	switch r.Type {
+	case "number":
+		if r.Number == nil {
+			return nil
+		}
+		return *r.Number
+	case "date":
+		if r.Date == nil {
+			return nil
+		}
+		return r.Date.Start
+	case "array":
+		values := make([]interface{}, 0, len(r.Array))
+		for _, pv := range r.Array {
+			values = append(values, pv.flatten())
+		}
+		return values
+	default:
+		return nil
+	}
 }
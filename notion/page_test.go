@@ -0,0 +1,1161 @@
+package notion
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var flattenTestPage = &Page{
+	Object: "page",
+	ID:     "ea8229fa-a781-4348-a154-de893e232e27",
+	Properties: map[string]PropertyValue{
+		"Name": {
+			ID:   "title",
+			Type: "title",
+			Title: []RichText{
+				{Type: "text", Text: &Text{Content: "Write more "}, PlainText: "Write more "},
+				{Type: "text", Text: &Text{Content: "integrations tests"}, PlainText: "integrations tests"},
+			},
+		},
+		"Status": {
+			ID:     "^OE@",
+			Type:   "select",
+			Select: &SelectPropertyValue{ID: "1", Name: "To Do", Color: "red"},
+		},
+		"Tag": {
+			ID:   "UHT}",
+			Type: "multi_select",
+			MultiSelect: []MultiSelectPropertyValue{
+				{ID: "0e8b9aa9-b1c5-4964-812d-207d0aec09cf", Name: "go", Color: "brown"},
+				{ID: "fc51b97d-458a-4bcc-8974-914b54afe2d6", Name: "software-engineering", Color: "default"},
+			},
+		},
+		"Needs ☕️?": {
+			ID:       "RRGi",
+			Type:     "checkbox",
+			Checkbox: true,
+		},
+		"Date Created": {
+			ID:          "'Y6<",
+			Type:        "created_time",
+			CreatedTime: "2021-05-20T09:18:00.000Z",
+		},
+	},
+}
+
+func TestPage_Flatten(t *testing.T) {
+	want := map[string]interface{}{
+		"Name":         "Write more integrations tests",
+		"Status":       "To Do",
+		"Tag":          []string{"go", "software-engineering"},
+		"Needs ☕️?":    true,
+		"Date Created": "2021-05-20T09:18:00.000Z",
+	}
+	got := flattenTestPage.Flatten()
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Flatten() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPage_FlattenFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields []string
+		want   map[string]interface{}
+	}{
+		{
+			name:   "should include only the requested properties",
+			fields: []string{"Name", "Status"},
+			want: map[string]interface{}{
+				"Name":   "Write more integrations tests",
+				"Status": "To Do",
+			},
+		},
+		{
+			name:   "should omit a requested property which is missing on the page",
+			fields: []string{"Name", "Priority"},
+			want: map[string]interface{}{
+				"Name": "Write more integrations tests",
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got := flattenTestPage.FlattenFields(tt.fields...)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("FlattenFields() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPage_Property(t *testing.T) {
+	page := &Page{Properties: map[string]PropertyValue{}}
+	for name, pv := range flattenTestPage.Properties {
+		page.Properties[name] = pv
+	}
+	page.Properties["Estimate"] = PropertyValue{ID: "ZHxA", Type: "number", Number: Float64(2.5)}
+
+	if got, ok := page.PropertyString("Name"); !ok || got != "Write more integrations tests" {
+		t.Errorf("PropertyString(Name) = %q, %v, want %q, true", got, ok, "Write more integrations tests")
+	}
+	if got, ok := page.PropertyNumber("Estimate"); !ok || got != 2.5 {
+		t.Errorf("PropertyNumber(Estimate) = %v, %v, want 2.5, true", got, ok)
+	}
+	if got, ok := page.PropertyBool("Needs ☕️?"); !ok || !got {
+		t.Errorf("PropertyBool(Needs ☕️?) = %v, %v, want true, true", got, ok)
+	}
+	if got, ok := page.PropertySelect("Status"); !ok || got != "To Do" {
+		t.Errorf("PropertySelect(Status) = %q, %v, want %q, true", got, ok, "To Do")
+	}
+	if _, ok := page.PropertyString("Status"); ok {
+		t.Errorf("PropertyString(Status) ok = true, want false for a select property")
+	}
+	if _, ok := page.PropertyNumber("Missing"); ok {
+		t.Errorf("PropertyNumber(Missing) ok = true, want false for a missing property")
+	}
+}
+
+func TestPropertyValue_Unmarshal(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want PropertyValue
+	}{
+		{
+			name: "should decode a rollup array of select values",
+			body: `{
+			  "id": "tqyU",
+			  "type": "rollup",
+			  "rollup": {
+				"type": "array",
+				"array": [
+				  {
+					"type": "select",
+					"select": {
+					  "id": "1",
+					  "name": "To Do",
+					  "color": "red"
+					}
+				  },
+				  {
+					"type": "select",
+					"select": {
+					  "id": "3",
+					  "name": "Done 🙌",
+					  "color": "green"
+					}
+				  }
+				],
+				"function": "show_original"
+			  }
+			}`,
+			want: PropertyValue{
+				ID:   "tqyU",
+				Type: "rollup",
+				Rollup: &RollupPropertyValue{
+					Type: "array",
+					Array: []PropertyValue{
+						{Type: "select", Select: &SelectPropertyValue{ID: "1", Name: "To Do", Color: "red"}},
+						{Type: "select", Select: &SelectPropertyValue{ID: "3", Name: "Done 🙌", Color: "green"}},
+					},
+					Function: "show_original",
+				},
+			},
+		},
+		{
+			name: "should decode a rollup array of dates",
+			body: `{
+			  "id": "Tn{c",
+			  "type": "rollup",
+			  "rollup": {
+				"type": "array",
+				"array": [
+				  {
+					"type": "date",
+					"date": {
+					  "start": "2021-05-20",
+					  "end": null
+					}
+				  },
+				  {
+					"type": "date",
+					"date": {
+					  "start": "2021-05-21T09:00:00.000+02:00",
+					  "end": "2021-05-21T10:00:00.000+02:00"
+					}
+				  }
+				],
+				"function": "show_original"
+			  }
+			}`,
+			want: PropertyValue{
+				ID:   "Tn{c",
+				Type: "rollup",
+				Rollup: &RollupPropertyValue{
+					Type: "array",
+					Array: []PropertyValue{
+						{Type: "date", Date: &DatePropertyValue{Start: "2021-05-20"}},
+						{
+							Type: "date",
+							Date: &DatePropertyValue{
+								Start: "2021-05-21T09:00:00.000+02:00",
+								End:   "2021-05-21T10:00:00.000+02:00",
+							},
+						},
+					},
+					Function: "show_original",
+				},
+			},
+		},
+		{
+			name: "should decode a relation",
+			body: `{
+			  "id": "Kg@c",
+			  "type": "relation",
+			  "relation": [
+				{"id": "7dbc2ec6-e4d2-4b36-b45e-6aaf3c2e79c0"},
+				{"id": "3e2df7a9-4a39-4c23-a0b7-b4a5e4a0d5ad"}
+			  ]
+			}`,
+			want: PropertyValue{
+				ID:   "Kg@c",
+				Type: "relation",
+				Relation: []RelationPropertyValue{
+					{ID: "7dbc2ec6-e4d2-4b36-b45e-6aaf3c2e79c0"},
+					{ID: "3e2df7a9-4a39-4c23-a0b7-b4a5e4a0d5ad"},
+				},
+			},
+		},
+		{
+			name: "should decode a number rollup",
+			body: `{
+			  "id": "l|W]",
+			  "type": "rollup",
+			  "rollup": {
+				"type": "number",
+				"number": 12.5,
+				"function": "sum"
+			  }
+			}`,
+			want: PropertyValue{
+				ID:   "l|W]",
+				Type: "rollup",
+				Rollup: &RollupPropertyValue{
+					Type:     "number",
+					Number:   Float64(12.5),
+					Function: "sum",
+				},
+			},
+		},
+		{
+			name: "should decode a url",
+			body: `{"id": "BZKU", "type": "url", "url": "https://developers.notion.com"}`,
+			want: PropertyValue{ID: "BZKU", Type: "url", URL: String("https://developers.notion.com")},
+		},
+		{
+			name: "should decode an email",
+			body: `{"id": "y@Yh", "type": "email", "email": "igor@example.com"}`,
+			want: PropertyValue{ID: "y@Yh", Type: "email", Email: String("igor@example.com")},
+		},
+		{
+			name: "should decode a phone number",
+			body: `{"id": "_A<p", "type": "phone_number", "phone_number": "415-000-1111"}`,
+			want: PropertyValue{ID: "_A<p", Type: "phone_number", PhoneNumber: String("415-000-1111")},
+		},
+		{
+			name: "should decode a status",
+			body: `{"id": "s%7CH%3B", "type": "status", "status": {"id": "b6b5b6d3", "name": "In progress", "color": "blue"}}`,
+			want: PropertyValue{
+				ID:     "s%7CH%3B",
+				Type:   "status",
+				Status: &SelectPropertyValue{ID: "b6b5b6d3", Name: "In progress", Color: "blue"},
+			},
+		},
+		{
+			name: "should tell an empty url from a missing one",
+			body: `{"id": "BZKU", "type": "url", "url": ""}`,
+			want: PropertyValue{ID: "BZKU", Type: "url", URL: String("")},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var got PropertyValue
+			if err := json.Unmarshal([]byte(tt.body), &got); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("json.Unmarshal() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPropertyValue_RichText(t *testing.T) {
+	body := `{
+	  "id": "NVv%5E",
+	  "type": "rich_text",
+	  "rich_text": [
+		{
+		  "type": "text",
+		  "text": {"content": "Some ", "link": null},
+		  "annotations": {"bold": false, "italic": false, "strikethrough": false, "underline": false, "code": false, "color": "default"},
+		  "plain_text": "Some ",
+		  "href": null
+		},
+		{
+		  "type": "text",
+		  "text": {"content": "bold text", "link": null},
+		  "annotations": {"bold": true, "italic": false, "strikethrough": false, "underline": false, "code": false, "color": "default"},
+		  "plain_text": "bold text",
+		  "href": null
+		}
+	  ]
+	}`
+
+	var got PropertyValue
+	if err := json.Unmarshal([]byte(body), &got); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	want := PropertyValue{
+		ID:   "NVv%5E",
+		Type: "rich_text",
+		RichText: []RichText{
+			{
+				Type:        "text",
+				Text:        &Text{Content: "Some "},
+				Annotations: &Annotations{Color: ColorDefault},
+				PlainText:   "Some ",
+			},
+			{
+				Type:        "text",
+				Text:        &Text{Content: "bold text"},
+				Annotations: &Annotations{Bold: true, Color: ColorDefault},
+				PlainText:   "bold text",
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("json.Unmarshal() mismatch (-want +got):\n%s", diff)
+	}
+	if plain := got.PlainText(); plain != "Some bold text" {
+		t.Errorf("PlainText() = %q, want %q", plain, "Some bold text")
+	}
+
+	encoded, err := json.Marshal(got)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var roundTripped PropertyValue
+	if err := json.Unmarshal(encoded, &roundTripped); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if diff := cmp.Diff(want, roundTripped); diff != "" {
+		t.Errorf("round trip mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPropertyValue_Number(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want float64
+	}{
+		{
+			name: "should keep the decimal part",
+			body: `{"id":"price","type":"number","number":19.99}`,
+			want: 19.99,
+		},
+		{
+			name: "should keep zero",
+			body: `{"id":"price","type":"number","number":0}`,
+			want: 0,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var got PropertyValue
+			if err := json.Unmarshal([]byte(tt.body), &got); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			gotNumber, ok := got.NumberValue()
+			if !ok || gotNumber != tt.want {
+				t.Errorf("NumberValue() = %v, %v, want %v, true", gotNumber, ok, tt.want)
+			}
+
+			encoded, err := json.Marshal(got)
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			if string(encoded) != tt.body {
+				t.Errorf("json.Marshal() = %s, want %s", encoded, tt.body)
+			}
+		})
+	}
+}
+
+func TestPropertyValue_MarshalURL(t *testing.T) {
+	got, err := json.Marshal(PropertyValue{Type: "url", URL: String("https://example.com")})
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	want := `{"type":"url","url":"https://example.com"}`
+	if string(got) != want {
+		t.Errorf("json.Marshal() = %s, want %s", got, want)
+	}
+}
+
+func TestPropertyValue_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		value PropertyValue
+		want  string
+	}{
+		{
+			name: "should emit only the select",
+			value: PropertyValue{
+				Type:     "select",
+				Title:    []RichText{},
+				Number:   Float64(0),
+				Select:   &SelectPropertyValue{Name: "Done"},
+				Checkbox: true,
+			},
+			want: `{"type":"select","select":{"name":"Done"}}`,
+		},
+		{
+			name:  "should emit only the number",
+			value: PropertyValue{ID: "price", Type: "number", Number: Float64(0), Title: []RichText{NewText("x")}},
+			want:  `{"id":"price","type":"number","number":0}`,
+		},
+		{
+			name:  "should emit an unchecked checkbox",
+			value: PropertyValue{Type: "checkbox", Checkbox: false},
+			want:  `{"type":"checkbox","checkbox":false}`,
+		},
+		{
+			name:  "should clear an empty number",
+			value: PropertyValue{Type: "number"},
+			want:  `{"type":"number","number":null}`,
+		},
+		{
+			name:  "should emit an empty title as a list",
+			value: PropertyValue{Type: "title"},
+			want:  `{"type":"title","title":[]}`,
+		},
+		{
+			name:  "should emit all the fields without a type",
+			value: PropertyValue{Checkbox: true},
+			want:  `{"checkbox":true}`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.value)
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("json.Marshal() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_ResolveRelationPages(t *testing.T) {
+	responses := map[string]string{
+		"/v1/pages/ea8229fa-a781-4348-a154-de893e232e27": `{
+		  "object": "page",
+		  "id": "ea8229fa-a781-4348-a154-de893e232e27",
+		  "properties": {
+			"Projects": {
+			  "id": "Kg@c",
+			  "type": "relation",
+			  "relation": [
+				{"id": "7dbc2ec6-e4d2-4b36-b45e-6aaf3c2e79c0"},
+				{"id": "3e2df7a9-4a39-4c23-a0b7-b4a5e4a0d5ad"}
+			  ]
+			}
+		  }
+		}`,
+		"/v1/pages/7dbc2ec6-e4d2-4b36-b45e-6aaf3c2e79c0": `{"object":"page","id":"7dbc2ec6-e4d2-4b36-b45e-6aaf3c2e79c0"}`,
+		"/v1/pages/3e2df7a9-4a39-4c23-a0b7-b4a5e4a0d5ad": `{"object":"page","id":"3e2df7a9-4a39-4c23-a0b7-b4a5e4a0d5ad"}`,
+	}
+	var (
+		mu       sync.Mutex
+		gotPaths []string
+	)
+	httpClient, _ := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		gotPaths = append(gotPaths, req.URL.Path)
+		mu.Unlock()
+		respBody, ok := responses[req.URL.Path]
+		if !ok {
+			return &http.Response{
+				StatusCode: 404,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"object":"error","status":404,"code":"object_not_found"}`)),
+			}, nil
+		}
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(respBody)),
+		}, nil
+	})
+	service := New("token", WithHTTPClient(httpClient))
+
+	gotPages, gotErr := service.ResolveRelationPages(context.Background(), "ea8229fa-a781-4348-a154-de893e232e27", "Kg@c", 2)
+	if gotErr != nil {
+		t.Fatalf("ResolveRelationPages() error = %v, wantErr <nil>", gotErr)
+	}
+
+	wantPages := []*Page{
+		{Object: "page", ID: "7dbc2ec6-e4d2-4b36-b45e-6aaf3c2e79c0"},
+		{Object: "page", ID: "3e2df7a9-4a39-4c23-a0b7-b4a5e4a0d5ad"},
+	}
+	if diff := cmp.Diff(wantPages, gotPages); diff != "" {
+		t.Errorf("ResolveRelationPages() mismatch (-want +got):\n%s", diff)
+	}
+	if len(gotPaths) != 3 {
+		t.Errorf("requests = %v, want 3 requests", gotPaths)
+	}
+}
+
+func TestService_RetrievePage_FilterProperties(t *testing.T) {
+	httpClient, capturedRequest := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"object":"page","id":"251d2b5f-268c-4de2-afe9-c71ff92ca95c"}`)),
+		}, nil
+	})
+	service := New("token", WithHTTPClient(httpClient))
+
+	if _, err := service.RetrievePage(context.Background(), "251d2b5f-268c-4de2-afe9-c71ff92ca95c", "abc", "def"); err != nil {
+		t.Fatalf("RetrievePage() error = %v, wantErr <nil>", err)
+	}
+
+	wantQuery := "filter_properties=abc&filter_properties=def"
+	if gotQuery := capturedRequest.URL.RawQuery; gotQuery != wantQuery {
+		t.Errorf("query = %v, want %v", gotQuery, wantQuery)
+	}
+}
+
+func TestService_ExpandRelations(t *testing.T) {
+	page := &Page{
+		Object: "page",
+		ID:     "ea8229fa-a781-4348-a154-de893e232e27",
+		Properties: map[string]PropertyValue{
+			"Projects": {
+				ID:   "Kg@c",
+				Type: "relation",
+				Relation: []RelationPropertyValue{
+					{ID: "7dbc2ec6-e4d2-4b36-b45e-6aaf3c2e79c0"},
+					{ID: "3e2df7a9-4a39-4c23-a0b7-b4a5e4a0d5ad"},
+				},
+			},
+		},
+	}
+	var requests int32
+	httpClient := &http.Client{Transport: RequestToResponse(func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&requests, 1)
+		id := strings.TrimPrefix(req.URL.Path, "/v1/pages/")
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(fmt.Sprintf(`{"object":"page","id":%q}`, id))),
+		}, nil
+	})}
+	service := New("token", WithHTTPClient(httpClient))
+
+	gotPages, gotErr := service.ExpandRelations(context.Background(), page, "Projects")
+	if gotErr != nil {
+		t.Fatalf("ExpandRelations() error = %v, wantErr <nil>", gotErr)
+	}
+
+	wantPages := []*Page{
+		{Object: "page", ID: "7dbc2ec6-e4d2-4b36-b45e-6aaf3c2e79c0"},
+		{Object: "page", ID: "3e2df7a9-4a39-4c23-a0b7-b4a5e4a0d5ad"},
+	}
+	if diff := cmp.Diff(wantPages, gotPages); diff != "" {
+		t.Errorf("ExpandRelations() mismatch (-want +got):\n%s", diff)
+	}
+	if got := atomic.LoadInt32(&requests); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+
+	if _, err := service.ExpandRelations(context.Background(), page, "Tags"); err == nil {
+		t.Errorf("ExpandRelations() error = <nil>, want an error for a missing property")
+	}
+}
+
+func TestService_ExpandRelations_Truncated(t *testing.T) {
+	page := &Page{
+		Object: "page",
+		ID:     "ea8229fa-a781-4348-a154-de893e232e27",
+		Properties: map[string]PropertyValue{
+			"Projects": {
+				ID:       "Kg@c",
+				Type:     "relation",
+				Relation: []RelationPropertyValue{{ID: "7dbc2ec6-e4d2-4b36-b45e-6aaf3c2e79c0"}},
+				HasMore:  true,
+			},
+		},
+	}
+	var (
+		mu       sync.Mutex
+		gotPaths []string
+	)
+	httpClient := &http.Client{Transport: RequestToResponse(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		gotPaths = append(gotPaths, req.URL.Path)
+		mu.Unlock()
+		respBody := fmt.Sprintf(`{"object":"page","id":%q}`, strings.TrimPrefix(req.URL.Path, "/v1/pages/"))
+		if req.URL.Path == "/v1/pages/ea8229fa-a781-4348-a154-de893e232e27/properties/Kg@c" {
+			respBody = `{
+			  "object": "list",
+			  "results": [
+				{"object": "property_item", "id": "Kg@c", "type": "relation", "relation": {"id": "7dbc2ec6-e4d2-4b36-b45e-6aaf3c2e79c0"}},
+				{"object": "property_item", "id": "Kg@c", "type": "relation", "relation": {"id": "3e2df7a9-4a39-4c23-a0b7-b4a5e4a0d5ad"}}
+			  ],
+			  "has_more": false
+			}`
+		}
+		return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBufferString(respBody))}, nil
+	})}
+	service := New("token", WithHTTPClient(httpClient))
+
+	gotPages, gotErr := service.ExpandRelations(context.Background(), page, "Projects")
+	if gotErr != nil {
+		t.Fatalf("ExpandRelations() error = %v, wantErr <nil>", gotErr)
+	}
+
+	wantPages := []*Page{
+		{Object: "page", ID: "7dbc2ec6-e4d2-4b36-b45e-6aaf3c2e79c0"},
+		{Object: "page", ID: "3e2df7a9-4a39-4c23-a0b7-b4a5e4a0d5ad"},
+	}
+	if diff := cmp.Diff(wantPages, gotPages); diff != "" {
+		t.Errorf("ExpandRelations() mismatch (-want +got):\n%s", diff)
+	}
+	if len(gotPaths) != 3 {
+		t.Errorf("requests = %v, want 3 requests", gotPaths)
+	}
+}
+
+func TestService_ResolveRelationPages_Truncated(t *testing.T) {
+	propertyItems := map[string]string{
+		"": `{
+		  "object": "list",
+		  "results": [
+			{"object": "property_item", "id": "Kg@c", "type": "relation", "relation": {"id": "7dbc2ec6-e4d2-4b36-b45e-6aaf3c2e79c0"}}
+		  ],
+		  "next_cursor": "cursor-2",
+		  "has_more": true
+		}`,
+		"cursor-2": `{
+		  "object": "list",
+		  "results": [
+			{"object": "property_item", "id": "Kg@c", "type": "relation", "relation": {"id": "3e2df7a9-4a39-4c23-a0b7-b4a5e4a0d5ad"}}
+		  ],
+		  "has_more": false
+		}`,
+	}
+	var (
+		mu       sync.Mutex
+		gotPaths []string
+	)
+	httpClient := &http.Client{Transport: RequestToResponse(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		gotPaths = append(gotPaths, req.URL.Path)
+		mu.Unlock()
+		var respBody string
+		switch {
+		case req.URL.Path == "/v1/pages/ea8229fa-a781-4348-a154-de893e232e27":
+			respBody = `{
+			  "object": "page",
+			  "id": "ea8229fa-a781-4348-a154-de893e232e27",
+			  "properties": {
+				"Projects": {
+				  "id": "Kg@c",
+				  "type": "relation",
+				  "relation": [{"id": "7dbc2ec6-e4d2-4b36-b45e-6aaf3c2e79c0"}],
+				  "has_more": true
+				}
+			  }
+			}`
+		case req.URL.Path == "/v1/pages/ea8229fa-a781-4348-a154-de893e232e27/properties/Kg@c":
+			respBody = propertyItems[req.URL.Query().Get("start_cursor")]
+		default:
+			id := strings.TrimPrefix(req.URL.Path, "/v1/pages/")
+			respBody = fmt.Sprintf(`{"object":"page","id":%q}`, id)
+		}
+		return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBufferString(respBody))}, nil
+	})}
+	service := New("token", WithHTTPClient(httpClient))
+
+	gotPages, gotErr := service.ResolveRelationPages(context.Background(), "ea8229fa-a781-4348-a154-de893e232e27", "Kg@c", 2)
+	if gotErr != nil {
+		t.Fatalf("ResolveRelationPages() error = %v, wantErr <nil>", gotErr)
+	}
+
+	wantPages := []*Page{
+		{Object: "page", ID: "7dbc2ec6-e4d2-4b36-b45e-6aaf3c2e79c0"},
+		{Object: "page", ID: "3e2df7a9-4a39-4c23-a0b7-b4a5e4a0d5ad"},
+	}
+	if diff := cmp.Diff(wantPages, gotPages); diff != "" {
+		t.Errorf("ResolveRelationPages() mismatch (-want +got):\n%s", diff)
+	}
+	if len(gotPaths) != 5 {
+		t.Errorf("requests = %v, want 5 requests", gotPaths)
+	}
+}
+
+func TestService_CreatePage(t *testing.T) {
+	httpClient, capturedRequest := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"object":"page","id":"251d2b5f-268c-4de2-afe9-c71ff92ca95c"}`)),
+		}, nil
+	})
+	service := New("token", WithHTTPClient(httpClient))
+
+	gotPage, gotErr := service.CreatePage(context.Background(), CreatePageRequest{
+		Parent: Parent{DatabaseID: "e65ccf14-e13b-48d1-a6d1-b14cd84c4bed"},
+		Properties: map[string]PropertyValue{
+			"Name": {Title: []RichText{{Type: "text", Text: &Text{Content: "Buy milk"}}}},
+		},
+	})
+	if gotErr != nil {
+		t.Fatalf("CreatePage() error = %v, wantErr <nil>", gotErr)
+	}
+
+	if capturedRequest.Method != http.MethodPost || capturedRequest.URL.Path != "/v1/pages" {
+		t.Errorf("request = %s %s, want POST /v1/pages", capturedRequest.Method, capturedRequest.URL.Path)
+	}
+	payload, _ := ioutil.ReadAll(capturedRequest.Body)
+	wantPayload := `{"parent":{"database_id":"e65ccf14-e13b-48d1-a6d1-b14cd84c4bed"},"properties":{"Name":{"title":[{"type":"text","text":{"content":"Buy milk"}}]}}}`
+	if string(payload) != wantPayload {
+		t.Errorf("payload = %s, want %s", payload, wantPayload)
+	}
+	wantPage := &Page{Object: "page", ID: "251d2b5f-268c-4de2-afe9-c71ff92ca95c"}
+	if diff := cmp.Diff(wantPage, gotPage); diff != "" {
+		t.Errorf("CreatePage() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestService_CreatePageWithContent(t *testing.T) {
+	httpClient, capturedRequest := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"object":"page","id":"251d2b5f-268c-4de2-afe9-c71ff92ca95c"}`)),
+		}, nil
+	})
+	service := New("token", WithHTTPClient(httpClient))
+
+	gotPage, gotErr := service.CreatePageWithContent(
+		context.Background(),
+		Parent{DatabaseID: "e65ccf14-e13b-48d1-a6d1-b14cd84c4bed"},
+		WriteProperties{}.SetTitle("Name", "Buy milk"),
+		[]Block{
+			{Type: "paragraph", Paragraph: &TextBlock{Text: []RichText{NewText("Semi-skimmed")}}},
+		},
+	)
+	if gotErr != nil {
+		t.Fatalf("CreatePageWithContent() error = %v, wantErr <nil>", gotErr)
+	}
+
+	if capturedRequest.Method != http.MethodPost || capturedRequest.URL.Path != "/v1/pages" {
+		t.Errorf("request = %s %s, want POST /v1/pages", capturedRequest.Method, capturedRequest.URL.Path)
+	}
+	payload, _ := ioutil.ReadAll(capturedRequest.Body)
+	wantPayload := `{"parent":{"database_id":"e65ccf14-e13b-48d1-a6d1-b14cd84c4bed"},` +
+		`"properties":{"Name":{"type":"title","title":[{"type":"text","text":{"content":"Buy milk"}}]}},` +
+		`"children":[{"type":"paragraph","paragraph":{"text":[{"type":"text","text":{"content":"Semi-skimmed"}}]}}]}`
+	if string(payload) != wantPayload {
+		t.Errorf("payload = %s, want %s", payload, wantPayload)
+	}
+	if gotPage.ID != "251d2b5f-268c-4de2-afe9-c71ff92ca95c" {
+		t.Errorf("CreatePageWithContent() = %+v, want the created page", gotPage)
+	}
+}
+
+func TestService_UpdatePage(t *testing.T) {
+	httpClient, capturedRequest := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"object":"page","id":"251d2b5f-268c-4de2-afe9-c71ff92ca95c"}`)),
+		}, nil
+	})
+	service := New("token", WithHTTPClient(httpClient))
+
+	gotPage, gotErr := service.UpdatePage(
+		context.Background(),
+		"251d2b5f-268c-4de2-afe9-c71ff92ca95c",
+		WriteProperties{}.SetCheckbox("Done", true),
+	)
+	if gotErr != nil {
+		t.Fatalf("UpdatePage() error = %v, wantErr <nil>", gotErr)
+	}
+
+	wantPath := "/v1/pages/251d2b5f-268c-4de2-afe9-c71ff92ca95c"
+	if capturedRequest.Method != http.MethodPatch || capturedRequest.URL.Path != wantPath {
+		t.Errorf("request = %s %s, want PATCH %s", capturedRequest.Method, capturedRequest.URL.Path, wantPath)
+	}
+	payload, _ := ioutil.ReadAll(capturedRequest.Body)
+	wantPayload := `{"properties":{"Done":{"type":"checkbox","checkbox":true}}}`
+	if string(payload) != wantPayload {
+		t.Errorf("payload = %s, want %s", payload, wantPayload)
+	}
+	wantPage := &Page{Object: "page", ID: "251d2b5f-268c-4de2-afe9-c71ff92ca95c"}
+	if diff := cmp.Diff(wantPage, gotPage); diff != "" {
+		t.Errorf("UpdatePage() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestService_UpdatePage_RetryOnConflict(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         []Option
+		wantRequests int
+		wantErrMsg   string
+	}{
+		{
+			name:         "should retry a conflict",
+			opts:         []Option{WithMaxRetries(2), WithBackoffBase(time.Millisecond), WithRetryOnConflict()},
+			wantRequests: 2,
+		},
+		{
+			name:         "should not retry a conflict by default",
+			opts:         []Option{WithMaxRetries(2), WithBackoffBase(time.Millisecond)},
+			wantRequests: 1,
+			wantErrMsg:   "application error: &{conflict_error Conflict occurred while saving. Please try again.}",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			httpClient, _ := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+				requests++
+				if requests == 1 {
+					return &http.Response{
+						StatusCode: 409,
+						Body: ioutil.NopCloser(bytes.NewBufferString(`{
+						  "object": "error",
+						  "status": 409,
+						  "code": "conflict_error",
+						  "message": "Conflict occurred while saving. Please try again."
+						}`)),
+					}, nil
+				}
+				return &http.Response{
+					StatusCode: 200,
+					Body:       ioutil.NopCloser(bytes.NewBufferString(`{"object":"page","id":"251d2b5f-268c-4de2-afe9-c71ff92ca95c"}`)),
+				}, nil
+			})
+			service := New("token", append([]Option{WithHTTPClient(httpClient)}, tt.opts...)...)
+
+			_, gotErr := service.UpdatePage(
+				context.Background(),
+				"251d2b5f-268c-4de2-afe9-c71ff92ca95c",
+				WriteProperties{}.SetCheckbox("Done", true),
+			)
+
+			if requests != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", requests, tt.wantRequests)
+			}
+			if tt.wantErrMsg != "" {
+				if gotErr == nil {
+					gotErr = fmt.Errorf("no error")
+				}
+				if !strings.Contains(gotErr.Error(), tt.wantErrMsg) {
+					t.Errorf("UpdatePage() error = %v, wantErr %v", gotErr, tt.wantErrMsg)
+				}
+			} else if gotErr != nil {
+				t.Errorf("UpdatePage() error = %v, wantErr <nil>", gotErr)
+			}
+		})
+	}
+}
+
+func TestPage_CreatedAt(t *testing.T) {
+	tests := []struct {
+		name        string
+		createdTime string
+		want        time.Time
+		wantErr     bool
+	}{
+		{
+			name:        "should parse the timestamp",
+			createdTime: "2021-05-20T09:19:00.000Z",
+			want:        time.Date(2021, 5, 20, 9, 19, 0, 0, time.UTC),
+		},
+		{
+			name:        "should fail on a malformed timestamp",
+			createdTime: "20 May 2021",
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Page{CreatedTime: tt.createdTime}
+			got, err := p.CreatedAt()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CreatedAt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("CreatedAt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_RetrievePageProperty(t *testing.T) {
+	tests := []struct {
+		name       string
+		propertyID string
+		page       Pagination
+		respBody   string
+		wantPath   string
+		wantQuery  string
+		wantItems  *PropertyItemList
+	}{
+		{
+			name:       "should retrieve a paginated title",
+			propertyID: "title",
+			page:       Pagination{PageSize: 2},
+			respBody: `{
+			  "object": "list",
+			  "results": [
+				{"object": "property_item", "id": "title", "type": "title", "title": {"type": "text", "text": {"content": "Write more "}, "plain_text": "Write more "}},
+				{"object": "property_item", "id": "title", "type": "title", "title": {"type": "text", "text": {"content": "tests"}, "plain_text": "tests"}}
+			  ],
+			  "next_cursor": "cursor-2",
+			  "has_more": true,
+			  "property_item": {"id": "title", "type": "title", "title": {}}
+			}`,
+			wantPath:  "/v1/pages/ea8229fa-a781-4348-a154-de893e232e27/properties/title",
+			wantQuery: "page_size=2",
+			wantItems: &PropertyItemList{
+				Object: "list",
+				Results: []PropertyItem{
+					{
+						Object: "property_item",
+						ID:     "title",
+						Type:   "title",
+						Title:  &RichText{Type: "text", Text: &Text{Content: "Write more "}, PlainText: "Write more "},
+					},
+					{
+						Object: "property_item",
+						ID:     "title",
+						Type:   "title",
+						Title:  &RichText{Type: "text", Text: &Text{Content: "tests"}, PlainText: "tests"},
+					},
+				},
+				NextCursor:   "cursor-2",
+				HasMore:      true,
+				PropertyItem: &PropertyItem{ID: "title", Type: "title", Title: &RichText{}},
+			},
+		},
+		{
+			name:       "should retrieve a single number",
+			propertyID: "Rw?S",
+			page:       Pagination{PageSize: 100},
+			respBody:   `{"object": "property_item", "id": "Rw?S", "type": "number", "number": 2.5}`,
+			wantPath:   "/v1/pages/ea8229fa-a781-4348-a154-de893e232e27/properties/Rw%3FS",
+			wantQuery:  "page_size=100",
+			wantItems: &PropertyItemList{
+				Object:  "property_item",
+				Results: []PropertyItem{{Object: "property_item", ID: "Rw?S", Type: "number", Number: Float64(2.5)}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient, capturedRequest := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: 200,
+					Body:       ioutil.NopCloser(bytes.NewBufferString(tt.respBody)),
+				}, nil
+			})
+			// the strict mode knows the fields of both the list and the single item
+			service := New("token", WithHTTPClient(httpClient), WithStrictDecode())
+
+			gotItems, gotErr := service.RetrievePageProperty(
+				context.Background(),
+				"ea8229fa-a781-4348-a154-de893e232e27",
+				tt.propertyID,
+				tt.page,
+			)
+			if gotErr != nil {
+				t.Fatalf("RetrievePageProperty() error = %v, wantErr <nil>", gotErr)
+			}
+
+			if gotPath := capturedRequest.URL.EscapedPath(); gotPath != tt.wantPath {
+				t.Errorf("path = %v, want %v", gotPath, tt.wantPath)
+			}
+			if gotQuery := capturedRequest.URL.RawQuery; gotQuery != tt.wantQuery {
+				t.Errorf("query = %v, want %v", gotQuery, tt.wantQuery)
+			}
+			if diff := cmp.Diff(tt.wantItems, gotItems); diff != "" {
+				t.Errorf("RetrievePageProperty() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPage_HasMoreProperties(t *testing.T) {
+	relation := make([]RelationPropertyValue, truncatedItems)
+	tests := []struct {
+		name string
+		page Page
+		want bool
+	}{
+		{
+			name: "should report a value flagged by the API",
+			page: Page{Properties: map[string]PropertyValue{
+				"Recipes": {Type: "relation", Relation: relation[:2], HasMore: true},
+			}},
+			want: true,
+		},
+		{
+			name: "should report a value at the truncation limit",
+			page: Page{Properties: map[string]PropertyValue{
+				"Recipes": {Type: "relation", Relation: relation},
+			}},
+			want: true,
+		},
+		{
+			name: "should report a rollup array at the truncation limit",
+			page: Page{Properties: map[string]PropertyValue{
+				"Ingredients": {Type: "rollup", Rollup: &RollupPropertyValue{
+					Type: "array", Array: make([]PropertyValue, truncatedItems),
+				}},
+			}},
+			want: true,
+		},
+		{
+			name: "should not report a long title, like the database",
+			page: Page{Properties: map[string]PropertyValue{
+				"Name": {Type: "title", Title: make([]RichText, truncatedItems)},
+			}},
+			want: false,
+		},
+		{
+			name: "should not report short values",
+			page: *flattenTestPage,
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.page.HasMoreProperties(); got != tt.want {
+				t.Errorf("HasMoreProperties() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_UpdatePages(t *testing.T) {
+	failingID := "3e2df7a9-4a39-4c23-a0b7-b4a5e4a0d5ad"
+	updates := map[string]map[string]PropertyValue{
+		"7dbc2ec6-e4d2-4b36-b45e-6aaf3c2e79c0": WriteProperties{}.SetCheckbox("Done", true),
+		failingID:                              WriteProperties{}.SetCheckbox("Done", true),
+		"251d2b5f-268c-4de2-afe9-c71ff92ca95c": WriteProperties{}.SetCheckbox("Done", true),
+	}
+	var requests int32
+	httpClient := &http.Client{Transport: RequestToResponse(func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&requests, 1)
+		id := strings.TrimPrefix(req.URL.Path, "/v1/pages/")
+		if id == failingID {
+			return &http.Response{
+				StatusCode: 400,
+				Body: ioutil.NopCloser(bytes.NewBufferString(`{
+				  "object": "error",
+				  "status": 400,
+				  "code": "validation_error",
+				  "message": "Done is not a property that exists."
+				}`)),
+			}, nil
+		}
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(fmt.Sprintf(`{"object":"page","id":%q}`, id))),
+		}, nil
+	})}
+	service := New("token", WithHTTPClient(httpClient))
+
+	gotErrs, gotErr := service.UpdatePages(context.Background(), updates, 2)
+	if gotErr != nil {
+		t.Fatalf("UpdatePages() error = %v, wantErr <nil>", gotErr)
+	}
+
+	if got := atomic.LoadInt32(&requests); got != 3 {
+		t.Errorf("requests = %d, want 3", got)
+	}
+	if len(gotErrs) != 1 {
+		t.Fatalf("UpdatePages() errors = %v, want a single error", gotErrs)
+	}
+	if err, ok := gotErrs[failingID]; !ok || !strings.Contains(err.Error(), "validation_error") {
+		t.Errorf("UpdatePages() errors[%s] = %v, want a validation_error", failingID, err)
+	}
+}
+
+func TestService_RetrievePages(t *testing.T) {
+	ids := []string{
+		"7dbc2ec6-e4d2-4b36-b45e-6aaf3c2e79c0",
+		"3e2df7a9-4a39-4c23-a0b7-b4a5e4a0d5ad",
+		"251d2b5f-268c-4de2-afe9-c71ff92ca95c",
+		"ea8229fa-a781-4348-a154-de893e232e27",
+		"e65ccf14-e13b-48d1-a6d1-b14cd84c4bed",
+	}
+	var inFlight, maxInFlight int32
+	httpClient := &http.Client{Transport: RequestToResponse(func(req *http.Request) (*http.Response, error) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		id := strings.TrimPrefix(req.URL.Path, "/v1/pages/")
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(fmt.Sprintf(`{"object":"page","id":%q}`, id))),
+		}, nil
+	})}
+	service := New("token", WithHTTPClient(httpClient))
+
+	gotPages, gotErr := service.RetrievePages(context.Background(), ids, 2)
+	if gotErr != nil {
+		t.Fatalf("RetrievePages() error = %v, wantErr <nil>", gotErr)
+	}
+
+	var gotIDs []string
+	for _, page := range gotPages {
+		gotIDs = append(gotIDs, page.ID)
+	}
+	if diff := cmp.Diff(ids, gotIDs); diff != "" {
+		t.Errorf("RetrievePages() ids mismatch (-want +got):\n%s", diff)
+	}
+	if got := atomic.LoadInt32(&maxInFlight); got > 2 {
+		t.Errorf("max requests in flight = %d, want <= 2", got)
+	}
+}
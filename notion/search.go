@@ -0,0 +1,138 @@
+package notion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// SearchFilter limits the search results to the given type of objects, Property is "object" and Value is either
+// "page" or "database"
+//
+// See https://developers.notion.com/reference/post-search
+type SearchFilter struct {
+	Property string `json:"property"`
+	Value    string `json:"value"`
+}
+
+// SearchSort orders the search results, Timestamp is "last_edited_time"
+//
+// See https://developers.notion.com/reference/post-search
+type SearchSort struct {
+	Direction SortDirection `json:"direction"`
+	Timestamp string        `json:"timestamp"`
+}
+
+// SearchItem is a single search result, either a page or a database as told by Object
+type SearchItem struct {
+	Object   string
+	Page     *Page
+	Database *Database
+}
+
+// UnmarshalJSON decodes the item into Page or Database depending on its object type
+func (i *SearchItem) UnmarshalJSON(data []byte) error {
+	var object struct {
+		Object string `json:"object"`
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return err
+	}
+	*i = SearchItem{Object: object.Object}
+	switch object.Object {
+	case "page":
+		i.Page = &Page{}
+		return json.Unmarshal(data, i.Page)
+	case "database":
+		i.Database = &Database{}
+		return json.Unmarshal(data, i.Database)
+	}
+	return fmt.Errorf("unknown search result object %q", object.Object)
+}
+
+// SearchResult is a response to the search endpoint
+//
+// See https://developers.notion.com/reference/post-search
+// See https://developers.notion.com/reference/pagination
+type SearchResult struct {
+	Object     string       `json:"object,omitempty"`
+	Type       string       `json:"type,omitempty"`
+	Results    []SearchItem `json:"results,omitempty"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+	HasMore    bool         `json:"has_more,omitempty"`
+}
+
+// EnvelopeType returns the type of the items the list holds, e.g. "page_or_database" for the search results
+//
+// It's empty if the API version in use doesn't report the list type.
+func (l *SearchResult) EnvelopeType() string {
+	return l.Type
+}
+
+// UnmarshalJSON decodes the list, null results are decoded as an empty slice
+func (l *SearchResult) UnmarshalJSON(data []byte) error {
+	type list SearchResult
+	if err := json.Unmarshal(data, (*list)(l)); err != nil {
+		return err
+	}
+	if l.Results == nil {
+		l.Results = []SearchItem{}
+	}
+	return nil
+}
+
+// More reports whether there are more results to fetch
+func (l *SearchResult) More() bool {
+	return l.HasMore
+}
+
+// Cursor returns the cursor of the next page of results, see Pagination.StartCursor
+func (l *SearchResult) Cursor() string {
+	return l.NextCursor
+}
+
+// Search returns the pages and databases shared with the integration with the title matching the query
+//
+// An empty query matches all of them. The filter and the sort are optional.
+//
+// See https://developers.notion.com/reference/post-search
+func (s *Service) Search(
+	ctx context.Context,
+	query string,
+	filter *SearchFilter,
+	sort *SearchSort,
+	page Pagination,
+) (*SearchResult, error) {
+	if err := page.validate(); err != nil {
+		return nil, err
+	}
+	payload := newSearchPayload(query, filter, sort, page)
+	result := &SearchResult{}
+	apiErr := &Error{}
+	if err := s.client.Do(ctx, http.MethodPost, "/search", nil, payload, result, apiErr); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+type searchPayload struct {
+	Query       string        `json:"query,omitempty"`
+	Filter      *SearchFilter `json:"filter,omitempty"`
+	Sort        *SearchSort   `json:"sort,omitempty"`
+	StartCursor *string       `json:"start_cursor,omitempty"`
+	PageSize    int           `json:"page_size,omitempty"`
+}
+
+func newSearchPayload(query string, filter *SearchFilter, sort *SearchSort, page Pagination) *searchPayload {
+	payload := &searchPayload{
+		Query:    query,
+		Filter:   filter,
+		Sort:     sort,
+		PageSize: page.PageSize,
+	}
+	if page.StartCursor != "" {
+		payload.StartCursor = &page.StartCursor
+	}
+	return payload
+}
@@ -0,0 +1,93 @@
+package notion
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestService_Search(t *testing.T) {
+	httpClient, capturedRequest := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 200,
+			Body: ioutil.NopCloser(bytes.NewBufferString(`{
+			  "object": "list",
+			  "type": "page_or_database",
+			  "results": [
+				{
+				  "object": "page",
+				  "id": "251d2b5f-268c-4de2-afe9-c71ff92ca95c",
+				  "parent": {"type": "database_id", "database_id": "e65ccf14-e13b-48d1-a6d1-b14cd84c4bed"}
+				},
+				{
+				  "object": "database",
+				  "id": "e65ccf14-e13b-48d1-a6d1-b14cd84c4bed",
+				  "title": [{"type": "text", "text": {"content": "Grocery List"}, "plain_text": "Grocery List"}]
+				}
+			  ],
+			  "next_cursor": "a1d8501e-1ac1-43e9-a6bd-ea9fe6c8822b",
+			  "has_more": true
+			}`)),
+		}, nil
+	})
+	service := New("token", WithHTTPClient(httpClient))
+
+	gotResult, gotErr := service.Search(
+		context.Background(),
+		"grocery",
+		nil,
+		&SearchSort{Direction: SortDesc, Timestamp: "last_edited_time"},
+		Pagination{PageSize: 10},
+	)
+	if gotErr != nil {
+		t.Fatalf("Search() error = %v, wantErr <nil>", gotErr)
+	}
+
+	if capturedRequest.Method != http.MethodPost || capturedRequest.URL.Path != "/v1/search" {
+		t.Errorf("request = %s %s, want POST /v1/search", capturedRequest.Method, capturedRequest.URL.Path)
+	}
+	payload, _ := ioutil.ReadAll(capturedRequest.Body)
+	wantPayload := `{"query":"grocery","sort":{"direction":"descending","timestamp":"last_edited_time"},"page_size":10}`
+	if string(payload) != wantPayload {
+		t.Errorf("payload = %s, want %s", payload, wantPayload)
+	}
+	wantResult := &SearchResult{
+		Object: "list",
+		Type:   "page_or_database",
+		Results: []SearchItem{
+			{
+				Object: "page",
+				Page: &Page{
+					Object: "page",
+					ID:     "251d2b5f-268c-4de2-afe9-c71ff92ca95c",
+					Parent: Parent{Type: "database_id", DatabaseID: "e65ccf14-e13b-48d1-a6d1-b14cd84c4bed"},
+				},
+			},
+			{
+				Object: "database",
+				Database: &Database{
+					Object: "database",
+					ID:     "e65ccf14-e13b-48d1-a6d1-b14cd84c4bed",
+					Title:  []RichText{{Type: "text", Text: &Text{Content: "Grocery List"}, PlainText: "Grocery List"}},
+				},
+			},
+		},
+		NextCursor: "a1d8501e-1ac1-43e9-a6bd-ea9fe6c8822b",
+		HasMore:    true,
+	}
+	if diff := cmp.Diff(wantResult, gotResult); diff != "" {
+		t.Errorf("Search() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSearchItem_UnmarshalJSON_UnknownObject(t *testing.T) {
+	var item SearchItem
+	err := item.UnmarshalJSON([]byte(`{"object": "block", "id": "b"}`))
+	if err == nil {
+		t.Fatalf("UnmarshalJSON() error = <nil>, want an error")
+	}
+}
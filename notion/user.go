@@ -0,0 +1,131 @@
+package notion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// User represents a user in a Notion workspace, either a person or a bot
+//
+// See https://developers.notion.com/reference/user
+type User struct {
+	Object    string  `json:"object,omitempty"`
+	ID        string  `json:"id,omitempty"`
+	Type      string  `json:"type,omitempty"`
+	Name      string  `json:"name,omitempty"`
+	AvatarURL string  `json:"avatar_url,omitempty"`
+	Person    *Person `json:"person,omitempty"`
+	Bot       *Bot    `json:"bot,omitempty"`
+}
+
+// Person contains the details specific to the users of type "person"
+//
+// See https://developers.notion.com/reference/user#people
+type Person struct {
+	Email string `json:"email,omitempty"`
+}
+
+// Bot contains the details specific to the users of type "bot"
+//
+// See https://developers.notion.com/reference/user#bots
+type Bot struct {
+	Owner         *BotOwner `json:"owner,omitempty"`
+	WorkspaceName string    `json:"workspace_name,omitempty"`
+}
+
+// BotOwner describes who owns the bot
+//
+// The owner is either the whole workspace (Type "workspace", Workspace set to true) or a single user (Type "user",
+// User set to the owning user).
+//
+// See https://developers.notion.com/reference/user#bots
+type BotOwner struct {
+	Type      string `json:"type,omitempty"`
+	Workspace bool   `json:"workspace,omitempty"`
+	User      *User  `json:"user,omitempty"`
+}
+
+// UserList is a response to the list users endpoint
+//
+// See https://developers.notion.com/reference/get-users
+// See https://developers.notion.com/reference/pagination
+type UserList struct {
+	HasMore    bool   `json:"has_more,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Results    []User `json:"results,omitempty"`
+}
+
+// UnmarshalJSON decodes the list, null results are decoded as an empty slice
+func (l *UserList) UnmarshalJSON(data []byte) error {
+	type list UserList
+	if err := json.Unmarshal(data, (*list)(l)); err != nil {
+		return err
+	}
+	if l.Results == nil {
+		l.Results = []User{}
+	}
+	return nil
+}
+
+// More reports whether there are more results to fetch
+func (l *UserList) More() bool {
+	return l.HasMore
+}
+
+// Cursor returns the cursor of the next page of results, see Pagination.StartCursor
+func (l *UserList) Cursor() string {
+	return l.NextCursor
+}
+
+// RetrieveUser retrieves a User object using the ID specified
+//
+// See https://developers.notion.com/reference/get-user
+func (s *Service) RetrieveUser(ctx context.Context, userID string) (*User, error) {
+	userID, err := normalizeID(userID)
+	if err != nil {
+		return nil, err
+	}
+	user := &User{}
+	apiErr := &Error{}
+	if err := s.client.Do(ctx, http.MethodGet, fmt.Sprintf("/users/%s", userID), nil, nil, user, apiErr); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// RetrieveBotUser retrieves the bot User associated with the API token
+//
+// See https://developers.notion.com/reference/get-self
+func (s *Service) RetrieveBotUser(ctx context.Context) (*User, error) {
+	user := &User{}
+	apiErr := &Error{}
+	if err := s.client.Do(ctx, http.MethodGet, "/users/me", nil, nil, user, apiErr); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Ping checks the API is reachable and accepts the token, e.g. for a readiness check at startup
+//
+// It retrieves the bot user. Use IsUnauthorized to tell a bad token from other failures.
+func (s *Service) Ping(ctx context.Context) error {
+	_, err := s.RetrieveBotUser(ctx)
+	return err
+}
+
+// ListUsers lists all the users in the workspace.
+//
+// See https://developers.notion.com/reference/get-users
+func (s *Service) ListUsers(ctx context.Context, page Pagination) (*UserList, error) {
+	if err := page.validate(); err != nil {
+		return nil, err
+	}
+	users := &UserList{}
+	apiErr := &Error{}
+	if err := s.client.Do(ctx, http.MethodGet, "/users", page.query(), nil, users, apiErr); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
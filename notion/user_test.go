@@ -0,0 +1,310 @@
+package notion
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"notion-go/client"
+)
+
+func TestUser_Unmarshal(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		wantUser *User
+	}{
+		{
+			name: "should decode a workspace-owned bot",
+			body: `{
+			  "object": "user",
+			  "id": "9188c6a5-7381-452f-b3dc-d4865aa89bdf",
+			  "name": "Test Integration",
+			  "avatar_url": null,
+			  "type": "bot",
+			  "bot": {
+				"owner": {
+				  "type": "workspace",
+				  "workspace": true
+				},
+				"workspace_name": "Igor's Notion"
+			  }
+			}`,
+			wantUser: &User{
+				Object: "user",
+				ID:     "9188c6a5-7381-452f-b3dc-d4865aa89bdf",
+				Name:   "Test Integration",
+				Type:   "bot",
+				Bot: &Bot{
+					Owner: &BotOwner{
+						Type:      "workspace",
+						Workspace: true,
+					},
+					WorkspaceName: "Igor's Notion",
+				},
+			},
+		},
+		{
+			name: "should decode a user-owned bot",
+			body: `{
+			  "object": "user",
+			  "id": "9188c6a5-7381-452f-b3dc-d4865aa89bdf",
+			  "name": "Test Integration",
+			  "avatar_url": null,
+			  "type": "bot",
+			  "bot": {
+				"owner": {
+				  "type": "user",
+				  "user": {
+					"object": "user",
+					"id": "e79a0b74-3aba-4149-9f74-0bb5791a6ee6",
+					"name": "Igor",
+					"avatar_url": "https://example.com/avatar.png",
+					"type": "person",
+					"person": {
+					  "email": "igor@example.com"
+					}
+				  }
+				}
+			  }
+			}`,
+			wantUser: &User{
+				Object: "user",
+				ID:     "9188c6a5-7381-452f-b3dc-d4865aa89bdf",
+				Name:   "Test Integration",
+				Type:   "bot",
+				Bot: &Bot{
+					Owner: &BotOwner{
+						Type: "user",
+						User: &User{
+							Object:    "user",
+							ID:        "e79a0b74-3aba-4149-9f74-0bb5791a6ee6",
+							Name:      "Igor",
+							AvatarURL: "https://example.com/avatar.png",
+							Type:      "person",
+							Person:    &Person{Email: "igor@example.com"},
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			gotUser := &User{}
+			if err := json.Unmarshal([]byte(tt.body), gotUser); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.wantUser, gotUser); diff != "" {
+				t.Errorf("json.Unmarshal() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestService_ListUsers(t *testing.T) {
+	httpClient, capturedRequest := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 200,
+			Body: ioutil.NopCloser(bytes.NewBufferString(`{
+			  "results": [
+				{
+				  "object": "user",
+				  "id": "e79a0b74-3aba-4149-9f74-0bb5791a6ee6",
+				  "type": "person",
+				  "person": {
+					"email": "igor@example.com"
+				  },
+				  "name": "Igor",
+				  "avatar_url": "https://example.com/avatar.png"
+				},
+				{
+				  "object": "user",
+				  "id": "9188c6a5-7381-452f-b3dc-d4865aa89bdf",
+				  "name": "Test Integration",
+				  "avatar_url": null,
+				  "type": "bot",
+				  "bot": {}
+				}
+			  ],
+			  "next_cursor": "fe2cc560-036c-44cd-90e8-294d5a74cebc",
+			  "has_more": true
+			}`)),
+		}, nil
+	})
+	service := New("token", WithHTTPClient(httpClient))
+
+	gotUsers, gotErr := service.ListUsers(context.Background(), Pagination{PageSize: 2})
+	if gotErr != nil {
+		t.Fatalf("ListUsers() error = %v, wantErr <nil>", gotErr)
+	}
+
+	wantURL := "https://api.notion.com/v1/users?page_size=2"
+	if gotURL := capturedRequest.URL.String(); gotURL != wantURL {
+		t.Errorf("url = %v, want %v", gotURL, wantURL)
+	}
+	wantUsers := &UserList{
+		HasMore:    true,
+		NextCursor: "fe2cc560-036c-44cd-90e8-294d5a74cebc",
+		Results: []User{
+			{
+				Object:    "user",
+				ID:        "e79a0b74-3aba-4149-9f74-0bb5791a6ee6",
+				Type:      "person",
+				Name:      "Igor",
+				AvatarURL: "https://example.com/avatar.png",
+				Person:    &Person{Email: "igor@example.com"},
+			},
+			{
+				Object: "user",
+				ID:     "9188c6a5-7381-452f-b3dc-d4865aa89bdf",
+				Type:   "bot",
+				Name:   "Test Integration",
+				Bot:    &Bot{},
+			},
+		},
+	}
+	if diff := cmp.Diff(wantUsers, gotUsers); diff != "" {
+		t.Errorf("ListUsers() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestService_RetrieveUser(t *testing.T) {
+	httpClient, capturedRequest := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 200,
+			Body: ioutil.NopCloser(bytes.NewBufferString(`{
+			  "object": "user",
+			  "id": "e79a0b74-3aba-4149-9f74-0bb5791a6ee6",
+			  "type": "person",
+			  "person": {
+				"email": "igor@example.com"
+			  },
+			  "name": "Igor",
+			  "avatar_url": null
+			}`)),
+		}, nil
+	})
+	service := New("token", WithHTTPClient(httpClient))
+
+	gotUser, gotErr := service.RetrieveUser(context.Background(), "e79a0b74-3aba-4149-9f74-0bb5791a6ee6")
+	if gotErr != nil {
+		t.Fatalf("RetrieveUser() error = %v, wantErr <nil>", gotErr)
+	}
+
+	wantPath := "/v1/users/e79a0b74-3aba-4149-9f74-0bb5791a6ee6"
+	if gotPath := capturedRequest.URL.Path; gotPath != wantPath {
+		t.Errorf("path = %v, want %v", gotPath, wantPath)
+	}
+	wantUser := &User{
+		Object: "user",
+		ID:     "e79a0b74-3aba-4149-9f74-0bb5791a6ee6",
+		Type:   "person",
+		Name:   "Igor",
+		Person: &Person{Email: "igor@example.com"},
+	}
+	if diff := cmp.Diff(wantUser, gotUser); diff != "" {
+		t.Errorf("RetrieveUser() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestService_RetrieveBotUser(t *testing.T) {
+	httpClient, capturedRequest := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 200,
+			Body: ioutil.NopCloser(bytes.NewBufferString(`{
+			  "object": "user",
+			  "id": "9188c6a5-7381-452f-b3dc-d4865aa89bdf",
+			  "name": "Test Integration",
+			  "avatar_url": null,
+			  "type": "bot",
+			  "bot": {
+				"owner": {
+				  "type": "workspace",
+				  "workspace": true
+				},
+				"workspace_name": "Igor's Notion"
+			  }
+			}`)),
+		}, nil
+	})
+	service := New("token", WithHTTPClient(httpClient))
+
+	gotUser, gotErr := service.RetrieveBotUser(context.Background())
+	if gotErr != nil {
+		t.Fatalf("RetrieveBotUser() error = %v, wantErr <nil>", gotErr)
+	}
+
+	wantPath := "/v1/users/me"
+	if gotPath := capturedRequest.URL.Path; gotPath != wantPath {
+		t.Errorf("path = %v, want %v", gotPath, wantPath)
+	}
+	if gotUser.Type != "bot" {
+		t.Errorf("Type = %v, want bot", gotUser.Type)
+	}
+	wantBot := &Bot{
+		Owner:         &BotOwner{Type: "workspace", Workspace: true},
+		WorkspaceName: "Igor's Notion",
+	}
+	if diff := cmp.Diff(wantBot, gotUser.Bot); diff != "" {
+		t.Errorf("RetrieveBotUser() bot mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestService_Ping(t *testing.T) {
+	tests := []struct {
+		name             string
+		respStatusCode   int
+		respBody         string
+		wantUnauthorized bool
+	}{
+		{
+			name:           "should succeed with a valid token",
+			respStatusCode: 200,
+			respBody:       `{"object":"user","id":"9188c6a5-7381-452f-b3dc-d4865aa89bdf","type":"bot","bot":{}}`,
+		},
+		{
+			name:             "should fail with an unauthorized error on a bad token",
+			respStatusCode:   401,
+			respBody:         `{"object":"error","status":401,"code":"unauthorized","message":"API token is invalid."}`,
+			wantUnauthorized: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient, capturedRequest := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: tt.respStatusCode,
+					Body:       ioutil.NopCloser(bytes.NewBufferString(tt.respBody)),
+				}, nil
+			})
+			service := New("token", WithHTTPClient(httpClient))
+
+			gotErr := service.Ping(context.Background())
+
+			if gotPath := capturedRequest.URL.Path; gotPath != "/v1/users/me" {
+				t.Errorf("path = %v, want /v1/users/me", gotPath)
+			}
+			if !tt.wantUnauthorized {
+				if gotErr != nil {
+					t.Errorf("Ping() error = %v, wantErr <nil>", gotErr)
+				}
+				return
+			}
+			var appErr client.ApplicationError
+			if !errors.As(gotErr, &appErr) || appErr.StatusCode != 401 {
+				t.Errorf("Ping() error = %v, want a 401 application error", gotErr)
+			}
+			if !IsUnauthorized(gotErr) {
+				t.Errorf("IsUnauthorized(%v) = false, want true", gotErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package notion
+
+import (
+	"fmt"
+	"sort"
+)
+
+// WriteProperties holds the property values to set when creating or updating a page
+//
+// It's built with the setters, which exist only for the property types the API accepts in writes, so that the
+// read-only values (formula, rollup, created_time, ...) can't be written by accident:
+//
+//	props := WriteProperties{}.SetTitle("Name", "Buy milk").SetCheckbox("Done", false)
+//
+// WriteProperties can be used wherever a map[string]PropertyValue is expected, e.g. in CreatePageRequest.
+//
+// The setters allocate a nil WriteProperties, so keep the value they return: var props WriteProperties; props =
+// props.SetTitle("Name", "Buy milk").
+type WriteProperties map[string]PropertyValue
+
+// set sets the property value, allocating w if it's nil
+func (w WriteProperties) set(name string, pv PropertyValue) WriteProperties {
+	if w == nil {
+		w = WriteProperties{}
+	}
+	w[name] = pv
+	return w
+}
+
+// SetTitle sets the title property to plain text content
+func (w WriteProperties) SetTitle(name, content string) WriteProperties {
+	return w.set(name, TitleValue(content))
+}
+
+// SetRichText sets the rich_text property to plain text content
+func (w WriteProperties) SetRichText(name, content string) WriteProperties {
+	return w.set(name, PropertyValue{Type: "rich_text", RichText: []RichText{NewText(content)}})
+}
+
+// SetNumber sets the number property
+func (w WriteProperties) SetNumber(name string, number float64) WriteProperties {
+	return w.set(name, PropertyValue{Type: "number", Number: &number})
+}
+
+// SetSelect sets the select property to the option with the given name
+func (w WriteProperties) SetSelect(name, option string) WriteProperties {
+	return w.set(name, PropertyValue{Type: "select", Select: &SelectPropertyValue{Name: option}})
+}
+
+// SetMultiSelect sets the multi_select property to the options with the given names
+func (w WriteProperties) SetMultiSelect(name string, options ...string) WriteProperties {
+	values := make([]MultiSelectPropertyValue, 0, len(options))
+	for _, option := range options {
+		values = append(values, MultiSelectPropertyValue{Name: option})
+	}
+	return w.set(name, PropertyValue{Type: "multi_select", MultiSelect: values})
+}
+
+// SetCheckbox sets the checkbox property
+func (w WriteProperties) SetCheckbox(name string, checked bool) WriteProperties {
+	return w.set(name, PropertyValue{Type: "checkbox", Checkbox: checked})
+}
+
+// SetDate sets the date property, end is empty unless the value is a range
+func (w WriteProperties) SetDate(name, start, end string) WriteProperties {
+	return w.set(name, PropertyValue{Type: "date", Date: &DatePropertyValue{Start: start, End: end}})
+}
+
+// SetRelation sets the relation property to the pages with the given IDs
+func (w WriteProperties) SetRelation(name string, pageIDs ...string) WriteProperties {
+	values := make([]RelationPropertyValue, 0, len(pageIDs))
+	for _, id := range pageIDs {
+		values = append(values, RelationPropertyValue{ID: id})
+	}
+	return w.set(name, PropertyValue{Type: "relation", Relation: values})
+}
+
+// SetURL sets the url property
+func (w WriteProperties) SetURL(name, url string) WriteProperties {
+	return w.set(name, PropertyValue{Type: "url", URL: &url})
+}
+
+// SetEmail sets the email property
+func (w WriteProperties) SetEmail(name, email string) WriteProperties {
+	return w.set(name, PropertyValue{Type: "email", Email: &email})
+}
+
+// SetPhoneNumber sets the phone_number property
+func (w WriteProperties) SetPhoneNumber(name, phoneNumber string) WriteProperties {
+	return w.set(name, PropertyValue{Type: "phone_number", PhoneNumber: &phoneNumber})
+}
+
+// readOnlyTypes are the property types computed by Notion, the API rejects writes to them
+var readOnlyTypes = map[string]bool{
+	"formula":          true,
+	"rollup":           true,
+	"created_time":     true,
+	"created_by":       true,
+	"last_edited_time": true,
+	"last_edited_by":   true,
+}
+
+// checkWritable returns an error naming the first (in alphabetical order) read-only property value
+func checkWritable(properties map[string]PropertyValue) error {
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		pv := properties[name]
+		if readOnlyTypes[pv.Type] || pv.Rollup != nil || pv.CreatedTime != "" || pv.LastEditedTime != "" {
+			return ClientError{Reason: fmt.Sprintf("property %q is read-only and can't be written", name)}
+		}
+	}
+	return nil
+}
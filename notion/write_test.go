@@ -0,0 +1,100 @@
+package notion
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteProperties(t *testing.T) {
+	props := WriteProperties{}.
+		SetTitle("Name", "Buy milk").
+		SetNumber("Price", 0).
+		SetSelect("Status", "To Do").
+		SetMultiSelect("Tag", "errand", "home").
+		SetCheckbox("Done", true).
+		SetURL("Shop", "https://example.com")
+
+	got, err := json.Marshal(props)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	want := `{"Done":{"type":"checkbox","checkbox":true},` +
+		`"Name":{"type":"title","title":[{"type":"text","text":{"content":"Buy milk"}}]},` +
+		`"Price":{"type":"number","number":0},` +
+		`"Shop":{"type":"url","url":"https://example.com"},` +
+		`"Status":{"type":"select","select":{"name":"To Do"}},` +
+		`"Tag":{"type":"multi_select","multi_select":[{"name":"errand"},{"name":"home"}]}}`
+	if string(got) != want {
+		t.Errorf("json.Marshal() = %s, want %s", got, want)
+	}
+}
+
+func TestWriteProperties_Nil(t *testing.T) {
+	var props WriteProperties
+	props = props.SetTitle("Name", "Buy milk")
+
+	if diff := cmp.Diff(WriteProperties{"Name": TitleValue("Buy milk")}, props); diff != "" {
+		t.Errorf("SetTitle() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestService_WriteReadOnlyProperty(t *testing.T) {
+	properties := map[string]PropertyValue{
+		"Name":  {Type: "title", Title: []RichText{{Type: "text", Text: &Text{Content: "Buy milk"}}}},
+		"Total": {Type: "formula"},
+	}
+	tests := []struct {
+		name  string
+		write func(s *Service) error
+	}{
+		{
+			name: "should reject a formula in CreatePage",
+			write: func(s *Service) error {
+				_, err := s.CreatePage(context.Background(), CreatePageRequest{
+					Parent:     Parent{DatabaseID: "e65ccf14-e13b-48d1-a6d1-b14cd84c4bed"},
+					Properties: properties,
+				})
+				return err
+			},
+		},
+		{
+			name: "should reject a formula in UpdatePage",
+			write: func(s *Service) error {
+				_, err := s.UpdatePage(context.Background(), "251d2b5f-268c-4de2-afe9-c71ff92ca95c", properties)
+				return err
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			httpClient, _ := RequestCapturingMockHttpClient(func(req *http.Request) (*http.Response, error) {
+				requests++
+				return nil, fmt.Errorf("unexpected request")
+			})
+			service := New("token", WithHTTPClient(httpClient))
+
+			gotErr := tt.write(service)
+
+			wantErrMsg := `property "Total" is read-only`
+			if gotErr == nil || !strings.Contains(gotErr.Error(), wantErrMsg) {
+				t.Errorf("error = %v, wantErr %v", gotErr, wantErrMsg)
+			}
+			var clientErr ClientError
+			if !errors.As(gotErr, &clientErr) {
+				t.Errorf("error = %T, want ClientError", gotErr)
+			}
+			if requests != 0 {
+				t.Errorf("requests = %d, want 0", requests)
+			}
+		})
+	}
+}
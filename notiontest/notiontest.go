@@ -0,0 +1,62 @@
+// Package notiontest helps testing the code using the notion package without talking to the Notion API
+//
+// The http client from NewCapturingClient is meant to be passed to notion.WithHTTPClient:
+//
+//	httpClient, captured := notiontest.NewCapturingClient(notiontest.RespondJSON(200, `{"object":"user","id":"..."}`))
+//	service := notion.New("token", notion.WithHTTPClient(httpClient))
+//	...
+//	if captured.URL.Path != "/v1/users/me" {
+//		...
+//	}
+package notiontest
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+)
+
+// RequestToResponse is a function which given the request produces a response or an error
+//
+// It implements http.RoundTripper, so it can be used as the transport of an http.Client.
+type RequestToResponse func(req *http.Request) (*http.Response, error)
+
+// RoundTrip method to implement http.RoundTripper interface
+func (f RequestToResponse) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// NewCapturingClient creates an http.Client which instead of making an http call uses respond to produce a response
+//
+// The returned request is updated with a copy of every request made, so it's the last one once the client is done.
+// Its body can be read to check the payload. The client isn't safe for concurrent use.
+func NewCapturingClient(respond RequestToResponse) (*http.Client, *http.Request) {
+	var capture http.Request
+	return &http.Client{
+		Transport: RequestToResponse(func(req *http.Request) (*http.Response, error) {
+			capture = *req.Clone(req.Context())
+			if req.Body != nil {
+				body, err := ioutil.ReadAll(req.Body)
+				if err != nil {
+					return nil, err
+				}
+				req.Body = ioutil.NopCloser(bytes.NewReader(body))
+				capture.Body = ioutil.NopCloser(bytes.NewReader(body))
+			}
+			return respond(req)
+		}),
+	}, &capture
+}
+
+// RespondJSON responds to every request with the status code and the JSON body, e.g. a fixture copied from the API
+// reference
+func RespondJSON(statusCode int, body string) RequestToResponse {
+	return func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: statusCode,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       ioutil.NopCloser(bytes.NewBufferString(body)),
+			Request:    req,
+		}, nil
+	}
+}
@@ -0,0 +1,55 @@
+package notiontest_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"notion-go/notion"
+	"notion-go/notiontest"
+)
+
+func TestNewCapturingClient(t *testing.T) {
+	httpClient, captured := notiontest.NewCapturingClient(notiontest.RespondJSON(200, `{
+	  "object": "page",
+	  "id": "251d2b5f-268c-4de2-afe9-c71ff92ca95c"
+	}`))
+	service := notion.New("token", notion.WithHTTPClient(httpClient))
+
+	page, err := service.CreatePage(context.Background(), notion.CreatePageRequest{
+		Parent:     notion.Parent{DatabaseID: "e65ccf14-e13b-48d1-a6d1-b14cd84c4bed"},
+		Properties: notion.WriteProperties{}.SetTitle("Name", "Buy milk"),
+	})
+	if err != nil {
+		t.Fatalf("CreatePage() error = %v, wantErr <nil>", err)
+	}
+
+	if captured.Method != http.MethodPost || captured.URL.Path != "/v1/pages" {
+		t.Errorf("request = %s %s, want POST /v1/pages", captured.Method, captured.URL.Path)
+	}
+	payload, _ := ioutil.ReadAll(captured.Body)
+	if !strings.Contains(string(payload), `"content":"Buy milk"`) {
+		t.Errorf("payload = %s, want the title", payload)
+	}
+	if page.ID != "251d2b5f-268c-4de2-afe9-c71ff92ca95c" {
+		t.Errorf("CreatePage() = %+v, want the canned page", page)
+	}
+}
+
+func TestRespondJSON_Error(t *testing.T) {
+	httpClient, _ := notiontest.NewCapturingClient(notiontest.RespondJSON(404, `{
+	  "object": "error",
+	  "status": 404,
+	  "code": "object_not_found",
+	  "message": "Could not find page with ID: 251d2b5f-268c-4de2-afe9-c71ff92ca95c."
+	}`))
+	service := notion.New("token", notion.WithHTTPClient(httpClient))
+
+	_, err := service.RetrievePage(context.Background(), "251d2b5f-268c-4de2-afe9-c71ff92ca95c")
+
+	if !notion.IsNotFound(err) {
+		t.Errorf("RetrievePage() error = %v, want not found", err)
+	}
+}